@@ -0,0 +1,155 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"k8s.io/apimachinery/pkg/util/yaml"
+
+	"github.com/crossplane-contrib/function-go-templating/input/v1beta1"
+	"github.com/crossplane-contrib/function-go-templating/pkg/render"
+
+	"github.com/crossplane/function-sdk-go"
+	"github.com/crossplane/function-sdk-go/errors"
+	fnv1 "github.com/crossplane/function-sdk-go/proto/v1"
+	"github.com/crossplane/function-sdk-go/resource"
+)
+
+// DiffCmd renders a request with two template sources and prints the
+// differences between the responses.
+type DiffCmd struct {
+	Request string `arg:"" help:"Path of a RunFunctionRequest YAML or JSON file."                                                  type:"existingfile"`
+	Before  string `arg:"" help:"Template source before the change: a directory of templates, or a GoTemplate input YAML file." type:"path"`
+	After   string `arg:"" help:"Template source after the change: a directory of templates, or a GoTemplate input YAML file."  type:"path"`
+
+	Renderer RendererFlags `embed:""`
+}
+
+// Run this command.
+func (c *DiffCmd) Run() error {
+	req, err := readRequest(c.Request)
+	if err != nil {
+		return err
+	}
+
+	log, err := function.NewLogger(false)
+	if err != nil {
+		return err
+	}
+	opts, err := c.Renderer.Options(log)
+	if err != nil {
+		return err
+	}
+
+	r := render.New(opts...)
+	before, err := renderWithSource(r, req, c.Before)
+	if err != nil {
+		return errors.Wrapf(err, "cannot render %s", c.Before)
+	}
+	after, err := renderWithSource(r, req, c.After)
+	if err != nil {
+		return errors.Wrapf(err, "cannot render %s", c.After)
+	}
+
+	changes, err := render.DiffResponses(before, after)
+	if err != nil {
+		return err
+	}
+
+	return printResponseChanges(os.Stdout, changes)
+}
+
+// readRequest reads a RunFunctionRequest from a YAML or JSON file.
+func readRequest(path string) (*fnv1.RunFunctionRequest, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // reading the supplied file is intended
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot read request")
+	}
+	j, err := yaml.ToJSON(data)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot convert request to JSON")
+	}
+	req := &fnv1.RunFunctionRequest{}
+	if err := protojson.Unmarshal(j, req); err != nil {
+		return nil, errors.Wrap(err, "cannot unmarshal request")
+	}
+
+	return req, nil
+}
+
+// renderWithSource runs the function with the supplied template source. A
+// directory replaces the template source of the request's input, keeping its
+// other settings. A file replaces the whole input.
+func renderWithSource(r *render.Renderer, req *fnv1.RunFunctionRequest, src string) (*fnv1.RunFunctionResponse, error) {
+	info, err := os.Stat(src)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot read template source")
+	}
+
+	in := &v1beta1.GoTemplate{}
+	if info.IsDir() {
+		if req.GetInput() != nil {
+			b, err := protojson.Marshal(req.GetInput())
+			if err != nil {
+				return nil, errors.Wrap(err, "cannot marshal request input")
+			}
+			if err := json.Unmarshal(b, in); err != nil {
+				return nil, errors.Wrap(err, "cannot unmarshal request input")
+			}
+		}
+		in.Source = v1beta1.FileSystemSource
+		in.FileSystem = &v1beta1.TemplateSourceFileSystem{DirPath: src}
+		in.Inline = nil
+		in.Environment = nil
+	} else {
+		data, err := os.ReadFile(src) //nolint:gosec // reading the supplied file is intended
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot read input")
+		}
+		if err := yaml.Unmarshal(data, in); err != nil {
+			return nil, errors.Wrap(err, "cannot unmarshal input")
+		}
+	}
+
+	s, err := resource.AsStruct(in)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot convert input")
+	}
+	// Don't modify the request, it's rendered with both sources.
+	clone := proto.Clone(req).(*fnv1.RunFunctionRequest) //nolint:forcetypeassert // Clone returns the type it's passed.
+	clone.Input = s
+
+	return r.Render(context.Background(), clone)
+}
+
+// printResponseChanges writes the changes grouped by part of the response.
+func printResponseChanges(w io.Writer, changes []render.ResponseChange) error {
+	if len(changes) == 0 {
+		_, err := fmt.Fprintln(w, "No changes.")
+		return err
+	}
+
+	titles := map[string]string{
+		"resources":  "Desired composed resources",
+		"status":     "Composite status",
+		"context":    "Context",
+		"conditions": "Conditions",
+		"results":    "Results",
+	}
+	b := &strings.Builder{}
+	for _, c := range changes {
+		fmt.Fprintf(b, "%s:\n", titles[c.Part])
+		for _, l := range c.Changes {
+			fmt.Fprintf(b, "  %s\n", l)
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+
+	return err
+}
@@ -37,6 +37,48 @@ type GoTemplate struct {
 	Environment *TemplateSourceEnvironment `json:"environment,omitempty"`
 	// Options to set for the template engine. Valid options are documented at https://pkg.go.dev/text/template#Template.Option
 	Options *[]string `json:"options,omitempty"`
+	// ResourceNaming derives the composition resource name of rendered
+	// resources that don't have the composition-resource-name annotation.
+	// +optional
+	ResourceNaming *ResourceNaming `json:"resourceNaming,omitempty"`
+	// SchemaValidation validates rendered composed resources against the
+	// OpenAPI schemas of their kinds.
+	// +optional
+	SchemaValidation *SchemaValidation `json:"schemaValidation,omitempty"`
+	// ErrorMode defines how problems with rendered documents are reported.
+	// FailFast returns the first problem. Aggregate processes all documents
+	// and returns all problems.
+	// +kubebuilder:validation:Enum=FailFast;Aggregate
+	// +kubebuilder:default=FailFast
+	// +optional
+	ErrorMode ErrorMode `json:"errorMode,omitempty"`
+	// FailurePolicy defines what happens when a rendered document is invalid.
+	// Fatal reports the problem according to the ErrorMode. SkipDocument
+	// skips the document with a warning result, and keeps the observed
+	// version of its composed resource.
+	// +kubebuilder:validation:Enum=Fatal;SkipDocument
+	// +kubebuilder:default=Fatal
+	// +optional
+	FailurePolicy FailurePolicy `json:"failurePolicy,omitempty"`
+	// ExecutionMode defines how the templates are executed. Combined executes
+	// all templates at once. PerDocument executes each inline.templates entry
+	// or file separately, so errors in one don't abort the others.
+	// +kubebuilder:validation:Enum=Combined;PerDocument
+	// +kubebuilder:default=Combined
+	// +optional
+	ExecutionMode ExecutionMode `json:"executionMode,omitempty"`
+	// Diff reports the changes of the rendered composed resources compared to
+	// the desired state of previous functions and the observed state.
+	// +optional
+	Diff *Diff `json:"diff,omitempty"`
+	// Mode defines whether the templates are rendered for a Composition or an
+	// Operation. Auto detects Operations by their watched resource or by the
+	// capabilities of Crossplane v2.2 and later. Set Operation for other
+	// Operations of Crossplane v2.0 and v2.1.
+	// +kubebuilder:validation:Enum=Auto;Composition;Operation
+	// +kubebuilder:default=Auto
+	// +optional
+	Mode Mode `json:"mode,omitempty"`
 }
 
 // TemplateSource defines the location of the source template.
@@ -81,3 +123,117 @@ type Delims struct {
 	// +optional
 	Right *string `json:"right,omitempty"`
 }
+
+// ErrorMode defines how problems with rendered documents are reported.
+type ErrorMode string
+
+const (
+	// FailFastErrorMode returns a fatal result for the first problem.
+	FailFastErrorMode ErrorMode = "FailFast"
+
+	// AggregateErrorMode processes all documents and returns a fatal result
+	// with all problems, plus a warning result for each problem.
+	AggregateErrorMode ErrorMode = "Aggregate"
+)
+
+// ExecutionMode defines how the templates are executed.
+type ExecutionMode string
+
+const (
+	// CombinedExecutionMode executes all templates at once.
+	CombinedExecutionMode ExecutionMode = "Combined"
+
+	// PerDocumentExecutionMode executes each template entry or file
+	// separately.
+	PerDocumentExecutionMode ExecutionMode = "PerDocument"
+)
+
+// Mode defines whether the templates are rendered for a Composition or an
+// Operation.
+type Mode string
+
+const (
+	// AutoMode detects whether the request was sent by an Operation.
+	AutoMode Mode = "Auto"
+
+	// CompositionMode renders the templates for a Composition.
+	CompositionMode Mode = "Composition"
+
+	// OperationMode renders the templates for an Operation.
+	OperationMode Mode = "Operation"
+)
+
+// FailurePolicy defines what happens when a rendered document is invalid.
+type FailurePolicy string
+
+const (
+	// FatalFailurePolicy returns a fatal result for invalid documents.
+	FatalFailurePolicy FailurePolicy = "Fatal"
+
+	// SkipDocumentFailurePolicy skips invalid documents with a warning result.
+	SkipDocumentFailurePolicy FailurePolicy = "SkipDocument"
+)
+
+// DiffOutput defines where the changes of composed resources are reported.
+type DiffOutput string
+
+const (
+	// ResultDiffOutput returns a Normal result for each changed composed
+	// resource.
+	ResultDiffOutput DiffOutput = "Result"
+
+	// LogDiffOutput writes a log line for each changed composed resource.
+	LogDiffOutput DiffOutput = "Log"
+)
+
+// Diff defines how the changes of composed resources are reported.
+type Diff struct {
+	// Output of the changes. Result returns a Normal result for each changed
+	// composed resource. Log writes a log line instead.
+	// +kubebuilder:validation:Enum=Result;Log
+	// +kubebuilder:default=Result
+	// +optional
+	Output DiffOutput `json:"output,omitempty"`
+}
+
+// SchemaValidation defines where the OpenAPI schemas used to validate
+// rendered composed resources are loaded from. Resources of kinds without a
+// schema are not validated.
+type SchemaValidation struct {
+	// DirPath is the folder path of CRD YAML files to load schemas from.
+	// +optional
+	DirPath string `json:"dirPath,omitempty"`
+	// RequireSchemas requests the schemas of kinds that are not found in
+	// DirPath from Crossplane.
+	// +optional
+	RequireSchemas bool `json:"requireSchemas,omitempty"`
+}
+
+// ResourceNamingPolicy defines how composition resource names are derived.
+type ResourceNamingPolicy string
+
+const (
+	// KindAndNamePolicy derives the name from the lower case kind and the
+	// metadata.name of the resource, e.g. bucket-my-bucket.
+	KindAndNamePolicy ResourceNamingPolicy = "KindAndName"
+
+	// TemplatePolicy derives the name by rendering a template expression.
+	TemplatePolicy ResourceNamingPolicy = "Template"
+
+	// FileAndIndexPolicy derives the name from the template file name and the
+	// index of the resource in the file, e.g. buckets-0.
+	FileAndIndexPolicy ResourceNamingPolicy = "FileAndIndex"
+)
+
+// ResourceNaming defines how composition resource names are derived.
+// +kubebuilder:validation:XValidation:rule="self.policy != 'Template' || has(self.template)",message="template must be set for the Template policy"
+type ResourceNaming struct {
+	// Policy used to derive the name.
+	// +kubebuilder:validation:Enum=KindAndName;Template;FileAndIndex
+	Policy ResourceNamingPolicy `json:"policy"`
+	// Template expression rendered for the Template policy. It is passed the
+	// rendered resource as .resource, its template file as .file and its
+	// index in the file as .index.
+	// +optional
+	Template string `json:"template,omitempty"`
+}
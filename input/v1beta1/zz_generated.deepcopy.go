@@ -33,6 +33,21 @@ func (in *Delims) DeepCopy() *Delims {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Diff) DeepCopyInto(out *Diff) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Diff.
+func (in *Diff) DeepCopy() *Diff {
+	if in == nil {
+		return nil
+	}
+	out := new(Diff)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GoTemplate) DeepCopyInto(out *GoTemplate) {
 	*out = *in
@@ -67,6 +82,21 @@ func (in *GoTemplate) DeepCopyInto(out *GoTemplate) {
 			copy(*out, *in)
 		}
 	}
+	if in.ResourceNaming != nil {
+		in, out := &in.ResourceNaming, &out.ResourceNaming
+		*out = new(ResourceNaming)
+		**out = **in
+	}
+	if in.SchemaValidation != nil {
+		in, out := &in.SchemaValidation, &out.SchemaValidation
+		*out = new(SchemaValidation)
+		**out = **in
+	}
+	if in.Diff != nil {
+		in, out := &in.Diff, &out.Diff
+		*out = new(Diff)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GoTemplate.
@@ -87,6 +117,36 @@ func (in *GoTemplate) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceNaming) DeepCopyInto(out *ResourceNaming) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceNaming.
+func (in *ResourceNaming) DeepCopy() *ResourceNaming {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceNaming)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SchemaValidation) DeepCopyInto(out *SchemaValidation) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SchemaValidation.
+func (in *SchemaValidation) DeepCopy() *SchemaValidation {
+	if in == nil {
+		return nil
+	}
+	out := new(SchemaValidation)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TemplateSourceEnvironment) DeepCopyInto(out *TemplateSourceEnvironment) {
 	*out = *in
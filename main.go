@@ -2,48 +2,105 @@
 package main
 
 import (
+	"context"
+	"os"
 	"time"
 
 	"github.com/alecthomas/kong"
 
+	"github.com/crossplane/crossplane-runtime/v2/pkg/logging"
+
+	"github.com/crossplane-contrib/function-go-templating/pkg/render"
+
 	"github.com/crossplane/function-sdk-go"
+	fnv1 "github.com/crossplane/function-sdk-go/proto/v1"
 	"github.com/crossplane/function-sdk-go/response"
 )
 
+// Function serves a Renderer over gRPC.
+type Function struct {
+	fnv1.UnimplementedFunctionRunnerServiceServer
+
+	renderer *render.Renderer
+}
+
+// RunFunction runs the Function.
+func (f *Function) RunFunction(ctx context.Context, req *fnv1.RunFunctionRequest) (*fnv1.RunFunctionResponse, error) {
+	return f.renderer.Render(ctx, req)
+}
+
 // CLI of this Function.
 type CLI struct {
+	Serve ServeCmd `cmd:"" default:"withargs" help:"Serve the Function over gRPC."`
+	Diff  DiffCmd  `cmd:""                    help:"Render a RunFunctionRequest with two template sources and print the differences."`
+}
+
+// RendererFlags configure the Renderer of both the Function and the diff
+// command, so that they render templates the same way.
+type RendererFlags struct {
+	TTL            string `default:"${defaultTTL}" help:"Function global setting for response TTL."`
+	DefaultSource  string `default:""              env:"FUNCTION_GO_TEMPLATING_DEFAULT_SOURCE"  help:"Default template source to use when input is not provided to the function."`
+	DefaultOptions string `default:""              env:"FUNCTION_GO_TEMPLATING_DEFAULT_OPTIONS" help:"Comma-separated default template options to use when input is not provided to the function."`
+
+	ExtensionsDir     string        `default:""    env:"FUNCTION_GO_TEMPLATING_EXTENSIONS_DIR"     help:"Directory of WASM modules that add template functions."`
+	ExtensionsTimeout time.Duration `default:"1s"  env:"FUNCTION_GO_TEMPLATING_EXTENSIONS_TIMEOUT" help:"Maximum duration of a call of a template function of a WASM module."`
+	ExtensionsMemory  uint32        `default:"256" env:"FUNCTION_GO_TEMPLATING_EXTENSIONS_MEMORY"  help:"Memory limit of a WASM module in 64KiB pages."`
+}
+
+// Options loads the template functions of the WASM modules in the extensions
+// directory, if any, and returns the options of the Renderer.
+func (f *RendererFlags) Options(log logging.Logger) ([]render.Option, error) {
+	ttl, err := time.ParseDuration(f.TTL)
+	if err != nil {
+		return nil, err
+	}
+
+	if f.ExtensionsDir != "" {
+		modules, err := render.LoadWASMFunctions(context.Background(), os.DirFS(f.ExtensionsDir), ".", render.WASMConfig{
+			Timeout:          f.ExtensionsTimeout,
+			MemoryLimitPages: f.ExtensionsMemory,
+		})
+		if err != nil {
+			return nil, err
+		}
+		log.Info("Loaded template functions of WASM modules", "dir", f.ExtensionsDir, "modules", modules)
+	}
+
+	return []render.Option{
+		render.WithLogger(log),
+		render.WithDefaultSource(f.DefaultSource),
+		render.WithDefaultOptions(f.DefaultOptions),
+		render.WithTTL(ttl),
+	}, nil
+}
+
+// ServeCmd serves this Function.
+type ServeCmd struct {
 	Debug bool `help:"Emit debug logs in addition to info logs." short:"d"`
 
-	Network            string `default:"tcp"                                                                                        help:"Network on which to listen for gRPC connections."`
-	Address            string `default:":9443"                                                                                      help:"Address at which to listen for gRPC connections."`
-	TLSCertsDir        string `env:"TLS_SERVER_CERTS_DIR"                                                                           help:"Directory containing server certs (tls.key, tls.crt) and the CA used to verify client certificates (ca.crt)"`
+	Network            string `default:"tcp"              help:"Network on which to listen for gRPC connections."`
+	Address            string `default:":9443"            help:"Address at which to listen for gRPC connections."`
+	TLSCertsDir        string `env:"TLS_SERVER_CERTS_DIR" help:"Directory containing server certs (tls.key, tls.crt) and the CA used to verify client certificates (ca.crt)"`
 	Insecure           bool   `help:"Run without mTLS credentials. If you supply this flag --tls-server-certs-dir will be ignored."`
-	TTL                string `default:"${defaultTTL}"                                                                              help:"Function global setting for response TTL."`
-	MaxRecvMessageSize int    `default:"4"                                                                                          env:"FUNCTION_GO_TEMPLATING_MAX_RECV_MESSAGE_SIZE"                                                                 help:"Maximum size of received messages in MB."`
-	DefaultSource      string `default:""                                                                                           env:"FUNCTION_GO_TEMPLATING_DEFAULT_SOURCE"                                                                        help:"Default template source to use when input is not provided to the function."`
-	DefaultOptions     string `default:""                                                                                           env:"FUNCTION_GO_TEMPLATING_DEFAULT_OPTIONS"                                                                       help:"Comma-separated default template options to use when input is not provided to the function."`
+	MaxRecvMessageSize int    `default:"4"                env:"FUNCTION_GO_TEMPLATING_MAX_RECV_MESSAGE_SIZE" help:"Maximum size of received messages in MB."`
+
+	Renderer RendererFlags `embed:""`
 }
 
 // Run this Function.
-func (c *CLI) Run() error {
+func (c *ServeCmd) Run() error {
 	log, err := function.NewLogger(c.Debug)
 	if err != nil {
 		return err
 	}
 
-	ttl, err := time.ParseDuration(c.TTL)
+	opts, err := c.Renderer.Options(log)
 	if err != nil {
 		return err
 	}
 
 	return function.Serve(
-		&Function{
-			log:            log,
-			fsys:           &osFS{},
-			defaultSource:  c.DefaultSource,
-			defaultOptions: c.DefaultOptions,
-			ttl:            ttl,
-		},
+		&Function{renderer: render.New(opts...)},
 		function.Listen(c.Network, c.Address),
 		function.MTLSCertificates(c.TLSCertsDir),
 		function.Insecure(c.Insecure),
@@ -0,0 +1,217 @@
+package render
+
+import (
+	"math"
+	"math/big"
+	"net"
+	"net/netip"
+
+	"github.com/crossplane/function-sdk-go/errors"
+)
+
+// The CIDR functions mirror the Terraform functions of the same name. See
+// https://developer.hashicorp.com/terraform/language/functions/cidrsubnet
+
+// cidrsubnet calculates a subnet address within the given IP network address
+// prefix.
+func cidrsubnet(prefix string, newbits, netnum any) (string, error) {
+	p, err := parsePrefix(prefix)
+	if err != nil {
+		return "", err
+	}
+	nb, err := toInt64(newbits)
+	if err != nil {
+		return "", errors.Wrap(err, "invalid newbits")
+	}
+	nn, err := toInt64(netnum)
+	if err != nil {
+		return "", errors.Wrap(err, "invalid netnum")
+	}
+
+	// Check newbits before adding it to the prefix length, which could
+	// overflow.
+	if nb < 0 || nb > int64(p.Addr().BitLen()-p.Bits()) {
+		return "", errors.Errorf("cannot add %d bits to prefix %s: must result in a prefix length between %d and %d", nb, p, p.Bits(), p.Addr().BitLen())
+	}
+	if nn < 0 || big.NewInt(nn).Cmp(pow2(int(nb))) >= 0 {
+		return "", errors.Errorf("prefix extension of %d bits does not accommodate a subnet numbered %d", nb, nn)
+	}
+	length := int64(p.Bits()) + nb
+
+	offset := new(big.Int).Lsh(big.NewInt(nn), uint(int64(p.Addr().BitLen())-length)) //nolint:gosec // length is at most the address length
+	addr := addAddr(p.Addr(), offset)
+
+	return netip.PrefixFrom(addr, int(length)).String(), nil
+}
+
+// cidrsubnets calculates a sequence of consecutive subnet addresses within
+// the given IP network address prefix, one for each of the supplied numbers
+// of additional bits.
+func cidrsubnets(prefix string, newbits ...any) ([]string, error) {
+	p, err := parsePrefix(prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	bitLen := p.Addr().BitLen()
+	base := addrToInt(p.Addr())
+	end := new(big.Int).Add(base, pow2(bitLen-p.Bits()))
+	pos := new(big.Int).Set(base)
+
+	subnets := make([]string, 0, len(newbits))
+	for i, v := range newbits {
+		nb, err := toInt64(v)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid newbits at index %d", i)
+		}
+		if nb < 1 || nb > int64(bitLen-p.Bits()) {
+			return nil, errors.Errorf("cannot add %d bits to prefix %s: must result in a prefix length between %d and %d", nb, p, p.Bits()+1, bitLen)
+		}
+		length := int64(p.Bits()) + nb
+
+		// Align the position to the start of the next subnet of this size.
+		size := pow2(bitLen - int(length))
+		pos.Add(pos, new(big.Int).Sub(size, big.NewInt(1)))
+		pos.Div(pos, size)
+		pos.Mul(pos, size)
+
+		next := new(big.Int).Add(pos, size)
+		if next.Cmp(end) > 0 {
+			return nil, errors.Errorf("not enough remaining address space in %s for a subnet with a prefix of %d bits after %v", p, length, subnets)
+		}
+
+		subnets = append(subnets, netip.PrefixFrom(intToAddr(pos, p.Addr().Is4()), int(length)).String())
+		pos = next
+	}
+
+	return subnets, nil
+}
+
+// cidrhost calculates a full host IP address for a given host number within
+// the given IP network address prefix. Negative host numbers count backwards
+// from the end of the prefix.
+func cidrhost(prefix string, hostnum any) (string, error) {
+	p, err := parsePrefix(prefix)
+	if err != nil {
+		return "", err
+	}
+	hn, err := toInt64(hostnum)
+	if err != nil {
+		return "", errors.Wrap(err, "invalid hostnum")
+	}
+
+	size := pow2(p.Addr().BitLen() - p.Bits())
+	n := big.NewInt(hn)
+	if hn < 0 {
+		n.Add(n, size)
+	}
+	if n.Sign() < 0 || n.Cmp(size) >= 0 {
+		return "", errors.Errorf("prefix of %d bits cannot accommodate a host numbered %d", p.Bits(), hn)
+	}
+
+	return addAddr(p.Addr(), n).String(), nil
+}
+
+// cidrnetmask converts an IPv4 address prefix into a subnet mask address.
+func cidrnetmask(prefix string) (string, error) {
+	p, err := parsePrefix(prefix)
+	if err != nil {
+		return "", err
+	}
+	if !p.Addr().Is4() {
+		return "", errors.Errorf("IPv6 prefix %s does not have a netmask", p)
+	}
+
+	return net.IP(net.CIDRMask(p.Bits(), 32)).String(), nil
+}
+
+// cidrcontains reports whether the given IP address or address prefix lies
+// entirely within the given IP network address prefix.
+func cidrcontains(prefix, addrOrPrefix string) (bool, error) {
+	p, err := parsePrefix(prefix)
+	if err != nil {
+		return false, err
+	}
+
+	if q, err := netip.ParsePrefix(addrOrPrefix); err == nil {
+		return q.Bits() >= p.Bits() && p.Contains(q.Masked().Addr()), nil
+	}
+
+	a, err := netip.ParseAddr(addrOrPrefix)
+	if err != nil {
+		return false, errors.Errorf("invalid IP address or prefix %q", addrOrPrefix)
+	}
+
+	return p.Contains(a), nil
+}
+
+// cidroverlaps reports whether the two given IP network address prefixes
+// share any addresses.
+func cidroverlaps(a, b string) (bool, error) {
+	p, err := parsePrefix(a)
+	if err != nil {
+		return false, err
+	}
+	q, err := parsePrefix(b)
+	if err != nil {
+		return false, err
+	}
+
+	return p.Overlaps(q), nil
+}
+
+// parsePrefix parses an IP network address prefix and masks off any host
+// bits.
+func parsePrefix(prefix string) (netip.Prefix, error) {
+	p, err := netip.ParsePrefix(prefix)
+	if err != nil {
+		return netip.Prefix{}, errors.Wrapf(err, "invalid CIDR prefix %q", prefix)
+	}
+
+	return p.Masked(), nil
+}
+
+func pow2(n int) *big.Int {
+	return new(big.Int).Lsh(big.NewInt(1), uint(n)) //nolint:gosec // n is never negative
+}
+
+func addrToInt(a netip.Addr) *big.Int {
+	return new(big.Int).SetBytes(a.AsSlice())
+}
+
+func intToAddr(i *big.Int, is4 bool) netip.Addr {
+	if is4 {
+		var b [4]byte
+		i.FillBytes(b[:])
+		return netip.AddrFrom4(b)
+	}
+
+	var b [16]byte
+	i.FillBytes(b[:])
+	return netip.AddrFrom16(b)
+}
+
+func addAddr(a netip.Addr, offset *big.Int) netip.Addr {
+	return intToAddr(new(big.Int).Add(addrToInt(a), offset), a.Is4())
+}
+
+// toInt64 converts the numeric types that commonly appear in templates to an
+// int64. Numbers read from the request are float64s, while literals in a
+// template are ints.
+func toInt64(v any) (int64, error) {
+	switch n := v.(type) {
+	case int:
+		return int64(n), nil
+	case int32:
+		return int64(n), nil
+	case int64:
+		return n, nil
+	case float64:
+		if n != math.Trunc(n) {
+			return 0, errors.Errorf("%v is not an integer", n)
+		}
+		return int64(n), nil
+	default:
+		return 0, errors.Errorf("%v (%T) is not an integer", v, v)
+	}
+}
@@ -0,0 +1,321 @@
+package render
+
+import (
+	"math"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func Test_cidrsubnet(t *testing.T) {
+	type args struct {
+		prefix  string
+		newbits any
+		netnum  any
+	}
+	type want struct {
+		rsp string
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"IPv4": {
+			reason: "Should calculate an IPv4 subnet",
+			args:   args{prefix: "172.16.0.0/12", newbits: 4, netnum: 2},
+			want:   want{rsp: "172.18.0.0/16"},
+		},
+		"IPv4Float": {
+			reason: "Should accept numbers read from the request",
+			args:   args{prefix: "10.1.2.0/24", newbits: float64(4), netnum: float64(15)},
+			want:   want{rsp: "10.1.2.240/28"},
+		},
+		"IPv6": {
+			reason: "Should calculate an IPv6 subnet",
+			args:   args{prefix: "fd00:fd12:3456:7890::/56", newbits: 16, netnum: 162},
+			want:   want{rsp: "fd00:fd12:3456:7800:a200::/72"},
+		},
+		"NetnumTooLarge": {
+			reason: "Should return an error if the subnet number does not fit the new bits",
+			args:   args{prefix: "10.0.0.0/16", newbits: 2, netnum: 4},
+			want:   want{err: cmpopts.AnyError},
+		},
+		"TooManyBits": {
+			reason: "Should return an error if the prefix length exceeds the address length",
+			args:   args{prefix: "10.0.0.0/16", newbits: 17, netnum: 0},
+			want:   want{err: cmpopts.AnyError},
+		},
+		"NewbitsOverflow": {
+			reason: "Should return an error rather than overflow if the new bits are huge",
+			args:   args{prefix: "10.0.0.0/8", newbits: int64(math.MaxInt64), netnum: 0},
+			want:   want{err: cmpopts.AnyError},
+		},
+		"InvalidPrefix": {
+			reason: "Should return an error if the prefix is invalid",
+			args:   args{prefix: "10.0.0.0", newbits: 8, netnum: 0},
+			want:   want{err: cmpopts.AnyError},
+		},
+		"NotAnInteger": {
+			reason: "Should return an error if a number is not an integer",
+			args:   args{prefix: "10.0.0.0/16", newbits: 8, netnum: 1.5},
+			want:   want{err: cmpopts.AnyError},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			rsp, err := cidrsubnet(tc.args.prefix, tc.args.newbits, tc.args.netnum)
+			if diff := cmp.Diff(tc.want.rsp, rsp); diff != "" {
+				t.Errorf("%s\ncidrsubnet(...): -want rsp, +got rsp:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("%s\ncidrsubnet(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func Test_cidrsubnets(t *testing.T) {
+	type args struct {
+		prefix  string
+		newbits []any
+	}
+	type want struct {
+		rsp []string
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"IPv4": {
+			reason: "Should allocate consecutive IPv4 subnets",
+			args:   args{prefix: "10.1.0.0/16", newbits: []any{4, 4, 8, 4}},
+			want:   want{rsp: []string{"10.1.0.0/20", "10.1.16.0/20", "10.1.32.0/24", "10.1.48.0/20"}},
+		},
+		"IPv6": {
+			reason: "Should allocate consecutive IPv6 subnets",
+			args:   args{prefix: "fd00:fd12:3456:7890::/56", newbits: []any{16, 16, 16, 32}},
+			want: want{rsp: []string{
+				"fd00:fd12:3456:7800::/72",
+				"fd00:fd12:3456:7800:100::/72",
+				"fd00:fd12:3456:7800:200::/72",
+				"fd00:fd12:3456:7800:300::/88",
+			}},
+		},
+		"NotEnoughSpace": {
+			reason: "Should return an error if the subnets do not fit the prefix",
+			args:   args{prefix: "10.0.0.0/24", newbits: []any{1, 1, 1}},
+			want:   want{err: cmpopts.AnyError},
+		},
+		"NewbitsOverflow": {
+			reason: "Should return an error rather than overflow if the new bits are huge",
+			args:   args{prefix: "10.0.0.0/8", newbits: []any{int64(math.MaxInt64)}},
+			want:   want{err: cmpopts.AnyError},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			rsp, err := cidrsubnets(tc.args.prefix, tc.args.newbits...)
+			if diff := cmp.Diff(tc.want.rsp, rsp); diff != "" {
+				t.Errorf("%s\ncidrsubnets(...): -want rsp, +got rsp:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("%s\ncidrsubnets(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func Test_cidrhost(t *testing.T) {
+	type args struct {
+		prefix  string
+		hostnum any
+	}
+	type want struct {
+		rsp string
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"IPv4": {
+			reason: "Should calculate an IPv4 host address",
+			args:   args{prefix: "10.12.112.0/20", hostnum: 268},
+			want:   want{rsp: "10.12.113.12"},
+		},
+		"IPv4Negative": {
+			reason: "Should count negative host numbers from the end of the prefix",
+			args:   args{prefix: "10.12.112.0/20", hostnum: -2},
+			want:   want{rsp: "10.12.127.254"},
+		},
+		"IPv6": {
+			reason: "Should calculate an IPv6 host address",
+			args:   args{prefix: "fd00:fd12:3456:7890:00a2::/72", hostnum: 34},
+			want:   want{rsp: "fd00:fd12:3456:7890::22"},
+		},
+		"HostnumTooLarge": {
+			reason: "Should return an error if the host number does not fit the prefix",
+			args:   args{prefix: "10.0.0.0/30", hostnum: 4},
+			want:   want{err: cmpopts.AnyError},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			rsp, err := cidrhost(tc.args.prefix, tc.args.hostnum)
+			if diff := cmp.Diff(tc.want.rsp, rsp); diff != "" {
+				t.Errorf("%s\ncidrhost(...): -want rsp, +got rsp:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("%s\ncidrhost(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func Test_cidrnetmask(t *testing.T) {
+	type want struct {
+		rsp string
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		prefix string
+		want   want
+	}{
+		"IPv4": {
+			reason: "Should return the netmask of an IPv4 prefix",
+			prefix: "172.16.0.0/12",
+			want:   want{rsp: "255.240.0.0"},
+		},
+		"IPv6": {
+			reason: "Should return an error for an IPv6 prefix",
+			prefix: "fd00::/8",
+			want:   want{err: cmpopts.AnyError},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			rsp, err := cidrnetmask(tc.prefix)
+			if diff := cmp.Diff(tc.want.rsp, rsp); diff != "" {
+				t.Errorf("%s\ncidrnetmask(...): -want rsp, +got rsp:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("%s\ncidrnetmask(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func Test_cidrcontains(t *testing.T) {
+	type args struct {
+		prefix       string
+		addrOrPrefix string
+	}
+	type want struct {
+		rsp bool
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"ContainsAddress": {
+			reason: "Should return true if the address is within the prefix",
+			args:   args{prefix: "10.0.0.0/16", addrOrPrefix: "10.0.12.1"},
+			want:   want{rsp: true},
+		},
+		"ContainsPrefix": {
+			reason: "Should return true if the prefix is within the prefix",
+			args:   args{prefix: "10.0.0.0/16", addrOrPrefix: "10.0.12.0/24"},
+			want:   want{rsp: true},
+		},
+		"LargerPrefix": {
+			reason: "Should return false if the prefix is larger than the prefix",
+			args:   args{prefix: "10.0.0.0/16", addrOrPrefix: "10.0.0.0/8"},
+			want:   want{rsp: false},
+		},
+		"DifferentFamily": {
+			reason: "Should return false for addresses of a different family",
+			args:   args{prefix: "10.0.0.0/16", addrOrPrefix: "fd00::1"},
+			want:   want{rsp: false},
+		},
+		"Invalid": {
+			reason: "Should return an error if the address is invalid",
+			args:   args{prefix: "10.0.0.0/16", addrOrPrefix: "10.0.0"},
+			want:   want{err: cmpopts.AnyError},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			rsp, err := cidrcontains(tc.args.prefix, tc.args.addrOrPrefix)
+			if diff := cmp.Diff(tc.want.rsp, rsp); diff != "" {
+				t.Errorf("%s\ncidrcontains(...): -want rsp, +got rsp:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("%s\ncidrcontains(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func Test_cidroverlaps(t *testing.T) {
+	type args struct {
+		a string
+		b string
+	}
+	type want struct {
+		rsp bool
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Overlapping": {
+			reason: "Should return true if the prefixes overlap",
+			args:   args{a: "10.0.0.0/16", b: "10.0.128.0/17"},
+			want:   want{rsp: true},
+		},
+		"Disjoint": {
+			reason: "Should return false if the prefixes do not overlap",
+			args:   args{a: "10.0.0.0/16", b: "10.1.0.0/16"},
+			want:   want{rsp: false},
+		},
+		"IPv6": {
+			reason: "Should support IPv6 prefixes",
+			args:   args{a: "fd00::/8", b: "fd00:1::/32"},
+			want:   want{rsp: true},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			rsp, err := cidroverlaps(tc.args.a, tc.args.b)
+			if diff := cmp.Diff(tc.want.rsp, rsp); diff != "" {
+				t.Errorf("%s\ncidroverlaps(...): -want rsp, +got rsp:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("%s\ncidroverlaps(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -1,4 +1,4 @@
-package main
+package render
 
 import (
 	xpv2 "github.com/crossplane/crossplane/apis/v2/core/v2"
@@ -31,17 +31,28 @@ func UpdateClaimConditions(rsp *fnv1.RunFunctionResponse, conditions ...Targeted
 	if rsp == nil {
 		return nil
 	}
+	if err := validateClaimConditions(conditions...); err != nil {
+		response.Fatal(rsp, err)
+		return errors.New("error updating response")
+	}
 	for _, c := range conditions {
-		if xpv2.IsSystemConditionType(c.Type) {
-			response.Fatal(rsp, errors.Errorf("cannot set ClaimCondition type: %s is a reserved Crossplane Condition", c.Type))
-			return errors.New("error updating response")
-		}
 		co := transformCondition(c)
 		UpdateResponseWithCondition(rsp, co)
 	}
 	return nil
 }
 
+// validateClaimConditions returns an error if a condition uses a type that is
+// reserved by Crossplane.
+func validateClaimConditions(conditions ...TargetedCondition) error {
+	for _, c := range conditions {
+		if xpv2.IsSystemConditionType(c.Type) {
+			return errors.Errorf("cannot set ClaimCondition type: %s is a reserved Crossplane Condition", c.Type)
+		}
+	}
+	return nil
+}
+
 // transformCondition converts a TargetedCondition to be compatible with the Protobuf SDK.
 func transformCondition(tc TargetedCondition) *fnv1.Condition {
 	c := &fnv1.Condition{
@@ -1,4 +1,4 @@
-package main
+package render
 
 import (
 	"dario.cat/mergo"
@@ -8,7 +8,7 @@ import (
 )
 
 // MergeContext merges existing Context with new values provided.
-func (f *Function) MergeContext(req *fnv1.RunFunctionRequest, val map[string]any) (map[string]any, error) {
+func (f *Renderer) MergeContext(req *fnv1.RunFunctionRequest, val map[string]any) (map[string]any, error) {
 	mergedContext := req.GetContext().AsMap()
 	if len(val) == 0 {
 		return mergedContext, nil
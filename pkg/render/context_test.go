@@ -1,4 +1,4 @@
-package main
+package render
 
 import (
 	"testing"
@@ -77,7 +77,7 @@ func TestMergeContext(t *testing.T) {
 	}
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
-			f := &Function{
+			f := &Renderer{
 				log: logging.NewNopLogger(),
 			}
 			rsp, err := f.MergeContext(tc.args.req, tc.args.val)
@@ -87,7 +87,7 @@ func TestMergeContext(t *testing.T) {
 			}
 
 			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
-				t.Errorf("%s\nf.RunFunction(...): -want err, +got err:\n%s", tc.reason, diff)
+				t.Errorf("%s\nf.Render(...): -want err, +got err:\n%s", tc.reason, diff)
 			}
 		})
 	}
@@ -0,0 +1,199 @@
+package render
+
+import (
+	"encoding/base64"
+	"fmt"
+	"text/template"
+
+	"github.com/crossplane/crossplane-runtime/v2/pkg/fieldpath"
+
+	"github.com/crossplane/function-sdk-go/errors"
+)
+
+// Connection details and credentials are bytes, which appear as base64
+// encoded strings in the request map. The functions below decode them. Their
+// errors never include the secret values themselves.
+
+// credentials are the decoded function credentials of a request, by name.
+type credentials map[string]map[string][]byte
+
+// decodeCredentials returns the data of all credentials of type
+// credentialData in req. It returns an error if a value is not valid base64.
+func decodeCredentials(req map[string]any) (credentials, error) {
+	var creds map[string]struct {
+		CredentialData *struct {
+			Data map[string]string `json:"data"`
+		} `json:"credentialData"`
+	}
+	if err := fieldpath.Pave(req).GetValueInto("credentials", &creds); err != nil {
+		if fieldpath.IsNotFound(err) {
+			return credentials{}, nil
+		}
+		return nil, errors.Wrap(err, "cannot get function credentials")
+	}
+
+	res := make(credentials, len(creds))
+	for name, cred := range creds {
+		if cred.CredentialData == nil {
+			continue
+		}
+		data := make(map[string][]byte, len(cred.CredentialData.Data))
+		for k, v := range cred.CredentialData.Data {
+			d, err := base64.StdEncoding.DecodeString(v)
+			if err != nil {
+				return nil, errors.Errorf("value of key %q of credential %q is not valid base64", k, name)
+			}
+			data[k] = d
+		}
+		res[name] = data
+	}
+
+	return res, nil
+}
+
+// getCredentialData returns the data of the supplied credential of req, or nil
+// if it does not exist.
+func getCredentialData(req map[string]any, credName string) (map[string][]byte, error) {
+	c, err := decodeCredentials(req)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.getCredentialData(req, credName), nil
+}
+
+// getCredentialValue returns the value of key in the supplied credential of
+// req as a string. It returns an error if the credential or key does not
+// exist.
+func getCredentialValue(req map[string]any, credName, key string) (string, error) {
+	c, err := decodeCredentials(req)
+	if err != nil {
+		return "", err
+	}
+
+	return c.getCredentialValue(req, credName, key)
+}
+
+// getCredentials returns the data of all credentials of req, with values as
+// strings rather than bytes.
+func getCredentials(req map[string]any) (map[string]any, error) {
+	c, err := decodeCredentials(req)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.getCredentials(req), nil
+}
+
+// functions returns the credential functions of a request that is rendered.
+// They replace the functions above, which decode the credentials of the
+// request they are passed on every call. They take the request as their first
+// argument like the other functions, but always return c.
+func (c credentials) functions() template.FuncMap {
+	return template.FuncMap{
+		"getCredentialData":  c.getCredentialData,
+		"getCredentialValue": c.getCredentialValue,
+		"getCredentials":     c.getCredentials,
+	}
+}
+
+// getCredentialData returns the data of the supplied credential, or nil if it
+// does not exist.
+func (c credentials) getCredentialData(_ map[string]any, credName string) map[string][]byte {
+	return c[credName]
+}
+
+// getCredentialValue returns the value of key in the supplied credential as a
+// string. It returns an error if the credential or key does not exist.
+func (c credentials) getCredentialValue(_ map[string]any, credName, key string) (string, error) {
+	data, ok := c[credName]
+	if !ok {
+		return "", errors.Errorf("credential %q not found", credName)
+	}
+	v, ok := data[key]
+	if !ok {
+		return "", errors.Errorf("key %q not found in credential %q", key, credName)
+	}
+
+	return string(v), nil
+}
+
+// getCredentials returns the data of all credentials, with values as strings
+// rather than bytes.
+func (c credentials) getCredentials(_ map[string]any) map[string]any {
+	res := make(map[string]any, len(c))
+	for name, data := range c {
+		m := make(map[string]any, len(data))
+		for k, v := range data {
+			m[k] = string(v)
+		}
+		res[name] = m
+	}
+
+	return res
+}
+
+// getConnectionDetail returns the decoded value of key in the connection
+// details of the supplied observed composed resource. It returns an empty
+// string if the resource or key does not exist, e.g. because the resource was
+// not created yet.
+func getConnectionDetail(req map[string]any, name, key string) (string, error) {
+	v, ok := getComposedConnectionDetails(req, name)[key]
+	if !ok {
+		return "", nil
+	}
+	s, ok := v.(string)
+	if !ok {
+		return "", errors.Errorf("connection detail %q of resource %q is not a base64 encoded string", key, name)
+	}
+	d, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return "", errors.Errorf("connection detail %q of resource %q is not valid base64", key, name)
+	}
+
+	return string(d), nil
+}
+
+// toSecretData base64 encodes the values of data, e.g. for the data of a
+// Secret or of CompositeConnectionDetails. Values may be strings, bytes,
+// numbers or booleans. Nil values are omitted.
+func toSecretData(data map[string]any) (map[string]any, error) {
+	res := make(map[string]any, len(data))
+	for k, v := range data {
+		var b []byte
+		switch t := v.(type) {
+		case nil:
+			continue
+		case string:
+			b = []byte(t)
+		case []byte:
+			b = t
+		case bool, int, int32, int64, float32, float64:
+			b = []byte(fmt.Sprint(t))
+		default:
+			return nil, errors.Errorf("cannot encode value of key %q of type %T: must be a string, bytes, number or boolean", k, v)
+		}
+		res[k] = base64.StdEncoding.EncodeToString(b)
+	}
+
+	return res, nil
+}
+
+// fromSecretData decodes the base64 encoded values of data, e.g. of an
+// observed Secret, to strings.
+func fromSecretData(data map[string]any) (map[string]any, error) {
+	res := make(map[string]any, len(data))
+	for k, v := range data {
+		s, ok := v.(string)
+		if !ok {
+			return nil, errors.Errorf("value of key %q is not a base64 encoded string", k)
+		}
+		d, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			return nil, errors.Errorf("value of key %q is not valid base64", k)
+		}
+		res[k] = string(d)
+	}
+
+	return res, nil
+}
@@ -0,0 +1,335 @@
+package render
+
+import (
+	"bytes"
+	"testing"
+	"text/template"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	fnv1 "github.com/crossplane/function-sdk-go/proto/v1"
+)
+
+func credentialsRequest(t *testing.T) map[string]any {
+	t.Helper()
+
+	req, err := convertToMap(&fnv1.RunFunctionRequest{
+		Credentials: map[string]*fnv1.Credentials{
+			"db-creds": {
+				Source: &fnv1.Credentials_CredentialData{
+					CredentialData: &fnv1.CredentialData{
+						Data: map[string][]byte{
+							"username": []byte("admin"),
+							"password": []byte("s3cr3t"),
+						},
+					},
+				},
+			},
+		},
+		Observed: &fnv1.State{
+			Resources: map[string]*fnv1.Resource{
+				"db": {
+					ConnectionDetails: map[string][]byte{
+						"endpoint": []byte("db.example.org:5432"),
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("cannot convert request to map: %v", err)
+	}
+
+	return req
+}
+
+func Test_getCredentialValue(t *testing.T) {
+	type args struct {
+		cred string
+		key  string
+	}
+	type want struct {
+		rsp string
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Value": {
+			reason: "Should return the decoded value",
+			args:   args{cred: "db-creds", key: "password"},
+			want:   want{rsp: "s3cr3t"},
+		},
+		"MissingCredential": {
+			reason: "Should return an error if the credential does not exist",
+			args:   args{cred: "other", key: "password"},
+			want:   want{err: cmpopts.AnyError},
+		},
+		"MissingKey": {
+			reason: "Should return an error if the key does not exist",
+			args:   args{cred: "db-creds", key: "token"},
+			want:   want{err: cmpopts.AnyError},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			creds, err := decodeCredentials(credentialsRequest(t))
+			if err != nil {
+				t.Fatalf("decodeCredentials(...): %v", err)
+			}
+			rsp, err := creds.getCredentialValue(nil, tc.args.cred, tc.args.key)
+			if diff := cmp.Diff(tc.want.rsp, rsp); diff != "" {
+				t.Errorf("%s\ngetCredentialValue(...): -want rsp, +got rsp:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("%s\ngetCredentialValue(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func Test_getCredentials(t *testing.T) {
+	creds, err := decodeCredentials(credentialsRequest(t))
+	if err != nil {
+		t.Fatalf("decodeCredentials(...): %v", err)
+	}
+	want := map[string]any{
+		"db-creds": map[string]any{
+			"username": "admin",
+			"password": "s3cr3t",
+		},
+	}
+	if diff := cmp.Diff(want, creds.getCredentials(nil)); diff != "" {
+		t.Errorf("getCredentials(...): -want, +got:\n%s", diff)
+	}
+	if diff := cmp.Diff(map[string]any{}, credentials{}.getCredentials(nil)); diff != "" {
+		t.Errorf("getCredentials(...): -want, +got:\n%s", diff)
+	}
+}
+
+func Test_decodeCredentials(t *testing.T) {
+	type want struct {
+		rsp credentials
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		req    map[string]any
+		want   want
+	}{
+		"Decoded": {
+			reason: "Should decode the values of the credentials",
+			req: map[string]any{"credentials": map[string]any{
+				"db-creds": map[string]any{"credentialData": map[string]any{"data": map[string]any{"username": "YWRtaW4="}}},
+			}},
+			want: want{rsp: credentials{"db-creds": {"username": []byte("admin")}}},
+		},
+		"NoCredentials": {
+			reason: "Should return no credentials if the request has none",
+			req:    map[string]any{},
+			want:   want{rsp: credentials{}},
+		},
+		"InvalidBase64": {
+			reason: "Should return an error if a value is not valid base64",
+			req: map[string]any{"credentials": map[string]any{
+				"db-creds": map[string]any{"credentialData": map[string]any{"data": map[string]any{"password": "s3cr3t!"}}},
+			}},
+			want: want{err: cmpopts.AnyError},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			rsp, err := decodeCredentials(tc.req)
+			if diff := cmp.Diff(tc.want.rsp, rsp); diff != "" {
+				t.Errorf("%s\ndecodeCredentials(...): -want rsp, +got rsp:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("%s\ndecodeCredentials(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+			if err != nil && bytes.Contains([]byte(err.Error()), []byte("s3cr3t")) {
+				t.Errorf("%s\ndecodeCredentials(...): error must not contain the secret value: %v", tc.reason, err)
+			}
+		})
+	}
+}
+
+func Test_getConnectionDetail(t *testing.T) {
+	type args struct {
+		req  map[string]any
+		name string
+		key  string
+	}
+	type want struct {
+		rsp string
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Detail": {
+			reason: "Should return the decoded connection detail",
+			args:   args{req: credentialsRequest(t), name: "db", key: "endpoint"},
+			want:   want{rsp: "db.example.org:5432"},
+		},
+		"MissingResource": {
+			reason: "Should return an empty string if the resource is not observed",
+			args:   args{req: credentialsRequest(t), name: "cache", key: "endpoint"},
+			want:   want{rsp: ""},
+		},
+		"InvalidBase64": {
+			reason: "Should return an error if the connection detail is not base64 encoded",
+			args: args{
+				req: map[string]any{
+					"observed": map[string]any{
+						"resources": map[string]any{
+							"db": map[string]any{
+								"connectionDetails": map[string]any{"endpoint": "not base64!"},
+							},
+						},
+					},
+				},
+				name: "db",
+				key:  "endpoint",
+			},
+			want: want{err: cmpopts.AnyError},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			rsp, err := getConnectionDetail(tc.args.req, tc.args.name, tc.args.key)
+			if diff := cmp.Diff(tc.want.rsp, rsp); diff != "" {
+				t.Errorf("%s\ngetConnectionDetail(...): -want rsp, +got rsp:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("%s\ngetConnectionDetail(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func Test_toSecretData(t *testing.T) {
+	type want struct {
+		rsp map[string]any
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		data   map[string]any
+		want   want
+	}{
+		"Encode": {
+			reason: "Should base64 encode all values and omit nil values",
+			data: map[string]any{
+				"username": "admin",
+				"cert":     []byte("bytes"),
+				"port":     int64(5432),
+				"tls":      true,
+				"unset":    nil,
+			},
+			want: want{rsp: map[string]any{
+				"username": "YWRtaW4=",
+				"cert":     "Ynl0ZXM=",
+				"port":     "NTQzMg==",
+				"tls":      "dHJ1ZQ==",
+			}},
+		},
+		"UnsupportedType": {
+			reason: "Should return an error for values that cannot be encoded",
+			data:   map[string]any{"nested": map[string]any{"a": "b"}},
+			want:   want{err: cmpopts.AnyError},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			rsp, err := toSecretData(tc.data)
+			if diff := cmp.Diff(tc.want.rsp, rsp); diff != "" {
+				t.Errorf("%s\ntoSecretData(...): -want rsp, +got rsp:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("%s\ntoSecretData(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func Test_fromSecretData(t *testing.T) {
+	type want struct {
+		rsp map[string]any
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		data   map[string]any
+		want   want
+	}{
+		"Decode": {
+			reason: "Should base64 decode all values",
+			data:   map[string]any{"username": "YWRtaW4="},
+			want:   want{rsp: map[string]any{"username": "admin"}},
+		},
+		"InvalidBase64": {
+			reason: "Should return an error without the value if a value is not base64 encoded",
+			data:   map[string]any{"password": "s3cr3t!"},
+			want:   want{err: cmpopts.AnyError},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			rsp, err := fromSecretData(tc.data)
+			if diff := cmp.Diff(tc.want.rsp, rsp); diff != "" {
+				t.Errorf("%s\nfromSecretData(...): -want rsp, +got rsp:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("%s\nfromSecretData(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+			if err != nil && bytes.Contains([]byte(err.Error()), []byte("s3cr3t")) {
+				t.Errorf("%s\nfromSecretData(...): error must not contain the secret value: %v", tc.reason, err)
+			}
+		})
+	}
+}
+
+func Test_credentialFunctions(t *testing.T) {
+	creds, err := decodeCredentials(credentialsRequest(t))
+	if err != nil {
+		t.Fatalf("decodeCredentials(...): %v", err)
+	}
+
+	cases := map[string]struct {
+		reason string
+		funcs  []template.FuncMap
+	}{
+		"RequestFunctions": {
+			reason: "The functions of the public constructor should decode the credentials of the supplied request",
+		},
+		"DecodedFunctions": {
+			reason: "The functions of decoded credentials should override the functions of the public constructor",
+			funcs:  []template.FuncMap{creds.functions()},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			tmpl, err := GetNewTemplateWithFunctionMaps(nil, tc.funcs...).Parse(`{{ getCredentialValue . "db-creds" "username" }}:{{ index (getCredentials .) "db-creds" "password" }}:{{ index (getCredentialData . "db-creds") "username" | toString }}`)
+			if err != nil {
+				t.Fatalf("%s\nParse(...): %v", tc.reason, err)
+			}
+			buf := &bytes.Buffer{}
+			if err := tmpl.Execute(buf, credentialsRequest(t)); err != nil {
+				t.Fatalf("%s\nExecute(...): %v", tc.reason, err)
+			}
+			if diff := cmp.Diff("admin:s3cr3t:admin", buf.String()); diff != "" {
+				t.Errorf("%s\nExecute(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
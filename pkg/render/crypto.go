@@ -0,0 +1,256 @@
+package render
+
+import (
+	"crypto/hmac"
+	"crypto/sha1" //nolint:gosec // SHA-1 fingerprints are still required, e.g. for AWS OIDC thumbprints
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"hash"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/crypto/blowfish"
+
+	"github.com/crossplane/function-sdk-go/errors"
+)
+
+// Sprig's crypto functions, e.g. genPrivateKey or bcrypt, generate new values
+// on every call. Composed resources are rendered on every reconcile, so the
+// functions below derive their output from their input instead.
+
+const (
+	passwordChars = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+	bcryptSaltSize    = 16
+	bcryptHashSize    = 23
+	bcryptMaxPassword = 72
+	bcryptBase64Chars = "./ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789"
+	bcryptMagicCipher = "OrpheanBeholderScryDoubt"
+)
+
+var bcryptEncoding = base64.NewEncoding(bcryptBase64Chars).WithPadding(base64.NoPadding)
+
+// hmacSha256 returns the hex encoded HMAC-SHA256 of message using key.
+func hmacSha256(key, message string) string {
+	m := hmac.New(sha256.New, []byte(key))
+	m.Write([]byte(message))
+
+	return hex.EncodeToString(m.Sum(nil))
+}
+
+// deriveHmac returns the hex encoded HMAC-SHA256 of the identity of the
+// observed composite resource using seed as key. It is unique to, and stable
+// for, the composite resource.
+func deriveHmac(req map[string]any, seed string) string {
+	return hmacSha256(seed, compositeIdentity(req))
+}
+
+// stablePassword returns an alphanumeric password of the supplied length that
+// is derived from seed and the identity of the observed composite resource.
+// Unlike stableRandAlphaNum it is not predictable without knowing the seed.
+func stablePassword(req map[string]any, seed string, length int) (string, error) {
+	if length < 1 {
+		return "", errors.Errorf("stablePassword requires a length of at least 1, got %d", length)
+	}
+
+	// Only use bytes that map evenly onto the characters to avoid a bias
+	// towards the first characters.
+	limit := byte(256 / len(passwordChars) * len(passwordChars))
+
+	b := make([]byte, 0, length)
+	id := compositeIdentity(req)
+	var counter [8]byte
+	for i := uint64(0); len(b) < length; i++ {
+		binary.BigEndian.PutUint64(counter[:], i)
+		m := hmac.New(sha256.New, []byte(seed))
+		m.Write([]byte(id))
+		m.Write(counter[:])
+		for _, c := range m.Sum(nil) {
+			if c >= limit || len(b) == length {
+				continue
+			}
+			b = append(b, passwordChars[int(c)%len(passwordChars)])
+		}
+	}
+
+	return string(b), nil
+}
+
+// stableBcrypt returns the bcrypt hash of password. The salt is derived from
+// salt and password, so the hash only changes if either of them changes.
+// Passing the UID of the composite resource as salt ensures composite
+// resources with the same password get different hashes.
+func stableBcrypt(password, salt string) (string, error) {
+	if len(password) > bcryptMaxPassword {
+		return "", bcrypt.ErrPasswordTooLong
+	}
+
+	sum := sha256.Sum256([]byte(salt + "/" + password))
+	s := sum[:bcryptSaltSize]
+
+	// Like other bcrypt implementations we include the trailing NUL in the
+	// key.
+	key := append([]byte(password), 0)
+	c, err := blowfish.NewSaltedCipher(key, s)
+	if err != nil {
+		return "", errors.Wrap(err, "cannot set up bcrypt cipher")
+	}
+	for range 1 << bcrypt.DefaultCost {
+		blowfish.ExpandKey(key, c)
+		blowfish.ExpandKey(s, c)
+	}
+
+	data := []byte(bcryptMagicCipher)
+	for i := 0; i < len(data); i += 8 {
+		for range 64 {
+			c.Encrypt(data[i:i+8], data[i:i+8])
+		}
+	}
+
+	// Like other bcrypt implementations we only encode 23 of the 24 bytes.
+	return fmt.Sprintf("$2a$%02d$%s%s", bcrypt.DefaultCost, bcryptEncoding.EncodeToString(s), bcryptEncoding.EncodeToString(data[:bcryptHashSize])), nil
+}
+
+// stableHtpasswd returns an htpasswd entry for user using stableBcrypt.
+func stableHtpasswd(user, password, salt string) (string, error) {
+	if strings.Contains(user, ":") {
+		return "", errors.Errorf("htpasswd user %q must not contain a colon", user)
+	}
+	h, err := stableBcrypt(password, salt)
+	if err != nil {
+		return "", err
+	}
+
+	return user + ":" + h, nil
+}
+
+// pemFingerprint returns the hex encoded fingerprint of the first PEM block in
+// data, e.g. a certificate or public key, using the supplied hash algorithm.
+func pemFingerprint(algorithm string, data string) (string, error) {
+	var h hash.Hash
+	switch strings.ToLower(algorithm) {
+	case "sha1":
+		h = sha1.New() //nolint:gosec // see import
+	case "sha256":
+		h = sha256.New()
+	case "sha512":
+		h = sha512.New()
+	default:
+		return "", errors.Errorf("unsupported fingerprint algorithm %q: must be one of sha1, sha256 or sha512", algorithm)
+	}
+
+	blocks, err := decodePEM(data)
+	if err != nil {
+		return "", err
+	}
+	h.Write(blocks[0].Bytes)
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// parseCertificate parses the first certificate in data, which may be PEM or
+// base64 encoded PEM as found in the data of an observed Secret.
+func parseCertificate(data string) (map[string]any, error) {
+	certs, err := parseCertificates(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(certs) == 0 {
+		return nil, errors.New("cannot find a certificate in PEM data")
+	}
+
+	return certs[0].(map[string]any), nil //nolint:forcetypeassert // parseCertificates only returns maps
+}
+
+// parseCertificates parses all certificates in data, e.g. a certificate chain.
+// Other PEM blocks, like private keys, are skipped.
+func parseCertificates(data string) ([]any, error) {
+	blocks, err := decodePEM(data)
+	if err != nil {
+		return nil, err
+	}
+
+	certs := make([]any, 0, len(blocks))
+	for _, b := range blocks {
+		if b.Type != "CERTIFICATE" {
+			continue
+		}
+		c, err := x509.ParseCertificate(b.Bytes)
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot parse certificate")
+		}
+		certs = append(certs, certificateToMap(c))
+	}
+
+	return certs, nil
+}
+
+// decodePEM returns all PEM blocks in data. data may be base64 encoded.
+func decodePEM(data string) ([]*pem.Block, error) {
+	raw := []byte(data)
+	if !strings.Contains(data, "-----BEGIN") {
+		d, err := base64.StdEncoding.DecodeString(strings.TrimSpace(data))
+		if err != nil {
+			return nil, errors.New("cannot find PEM data")
+		}
+		raw = d
+	}
+
+	var blocks []*pem.Block
+	for {
+		var b *pem.Block
+		b, raw = pem.Decode(raw)
+		if b == nil {
+			break
+		}
+		blocks = append(blocks, b)
+	}
+	if len(blocks) == 0 {
+		return nil, errors.New("cannot find PEM data")
+	}
+
+	return blocks, nil
+}
+
+func certificateToMap(c *x509.Certificate) map[string]any {
+	ips := make([]any, 0, len(c.IPAddresses))
+	for _, ip := range c.IPAddresses {
+		ips = append(ips, ip.String())
+	}
+	uris := make([]any, 0, len(c.URIs))
+	for _, u := range c.URIs {
+		uris = append(uris, u.String())
+	}
+	sha := sha256.Sum256(c.Raw)
+
+	return map[string]any{
+		"subject":           c.Subject.String(),
+		"commonName":        c.Subject.CommonName,
+		"issuer":            c.Issuer.String(),
+		"serialNumber":      c.SerialNumber.String(),
+		"notBefore":         c.NotBefore.UTC().Format(time.RFC3339),
+		"notAfter":          c.NotAfter.UTC().Format(time.RFC3339),
+		"dnsNames":          toAnySlice(c.DNSNames),
+		"ipAddresses":       ips,
+		"emailAddresses":    toAnySlice(c.EmailAddresses),
+		"uris":              uris,
+		"isCA":              c.IsCA,
+		"sha256Fingerprint": hex.EncodeToString(sha[:]),
+	}
+}
+
+func toAnySlice(s []string) []any {
+	out := make([]any, 0, len(s))
+	for _, v := range s {
+		out = append(out, v)
+	}
+
+	return out
+}
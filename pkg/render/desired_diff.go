@@ -0,0 +1,257 @@
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/crossplane-contrib/function-go-templating/input/v1beta1"
+
+	"github.com/crossplane/function-sdk-go/errors"
+	"github.com/crossplane/function-sdk-go/logging"
+	fnv1 "github.com/crossplane/function-sdk-go/proto/v1"
+	"github.com/crossplane/function-sdk-go/request"
+	"github.com/crossplane/function-sdk-go/resource"
+	"github.com/crossplane/function-sdk-go/response"
+)
+
+// maxDiffValueLength is the length after which values in a diff are
+// truncated.
+const maxDiffValueLength = 60
+
+// redactedDiffValue replaces redacted values in a diff.
+const redactedDiffValue = "<redacted>"
+
+// A redaction determines which values of a diff are redacted. Diffs of
+// desired composed resources end up in Events, so they must not contain
+// secret values.
+type redaction int
+
+const (
+	// redactNone reports all values.
+	redactNone redaction = iota
+	// redactData redacts the values of data and stringData fields, which
+	// commonly contain secret values, e.g. of a Secret wrapped in an Object.
+	redactData
+	// redactAll redacts all values.
+	redactAll
+)
+
+// field returns the redaction of the supplied field.
+func (r redaction) field(key string) redaction {
+	if r == redactData && (key == "data" || key == "stringData") {
+		return redactAll
+	}
+
+	return r
+}
+
+// redactionOf returns the redaction of the supplied resource. All values of
+// Secrets are redacted.
+func redactionOf(obj map[string]any) redaction {
+	if obj["apiVersion"] == "v1" && obj["kind"] == "Secret" {
+		return redactAll
+	}
+
+	return redactData
+}
+
+// A resourceDiff is the change of a desired composed resource compared to the
+// desired state of previous functions and the observed state.
+type resourceDiff struct {
+	Name     resource.Name
+	Desired  []string
+	Observed []string
+}
+
+// String returns the changes on one line.
+func (d resourceDiff) String() string {
+	parts := make([]string, 0, 2)
+	if len(d.Desired) > 0 {
+		parts = append(parts, "compared to desired: "+strings.Join(d.Desired, ", "))
+	}
+	if len(d.Observed) > 0 {
+		parts = append(parts, "compared to observed: "+strings.Join(d.Observed, ", "))
+	}
+
+	return fmt.Sprintf("composed resource %q changes %s", d.Name, strings.Join(parts, "; "))
+}
+
+// diffDesiredComposed returns the changes of the rendered desired composed
+// resources compared to the desired composed resources of previous functions
+// and the observed composed resources, ordered by name. Resources of previous
+// functions that were not rendered are not compared. Observed resources that
+// are not desired at all are reported as deleted. Fields that are only
+// observed, like the status, are not compared, because the function doesn't
+// own them.
+func diffDesiredComposed(req *fnv1.RunFunctionRequest, desired map[resource.Name]*resource.DesiredComposed, rendered map[resource.Name]bool) ([]resourceDiff, error) {
+	previous, err := request.GetDesiredComposedResources(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot get desired composed resources")
+	}
+	observed, err := request.GetObservedComposedResources(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot get observed composed resources")
+	}
+
+	names := make([]resource.Name, 0, len(rendered))
+	for name := range rendered {
+		names = append(names, name)
+	}
+	for name := range observed {
+		if _, ok := desired[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	diffs := make([]resourceDiff, 0, len(names))
+	for _, name := range names {
+		d := resourceDiff{Name: name}
+		cd, ok := desired[name]
+		if !ok {
+			d.Observed = []string{"deleted"}
+			diffs = append(diffs, d)
+			continue
+		}
+		after, err := normalize(cd.Resource.Object)
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot compare composed resource %q", name)
+		}
+		r := redactionOf(after)
+		if pd, ok := previous[name]; ok {
+			before, err := normalize(pd.Resource.Object)
+			if err != nil {
+				return nil, errors.Wrapf(err, "cannot compare composed resource %q", name)
+			}
+			d.Desired = diffValues("", before, after, false, r)
+		} else {
+			d.Desired = []string{"added"}
+		}
+		if oc, ok := observed[name]; ok {
+			before, err := normalize(oc.Resource.Object)
+			if err != nil {
+				return nil, errors.Wrapf(err, "cannot compare composed resource %q", name)
+			}
+			d.Observed = diffValues("", before, after, true, max(r, redactionOf(before)))
+		} else {
+			d.Observed = []string{"created"}
+		}
+		if len(d.Desired) == 0 && len(d.Observed) == 0 {
+			continue
+		}
+		diffs = append(diffs, d)
+	}
+
+	return diffs, nil
+}
+
+// reportDesiredDiff emits the changes of the rendered desired composed
+// resources as a Normal result or a log line per changed resource.
+func reportDesiredDiff(rsp *fnv1.RunFunctionResponse, log logging.Logger, req *fnv1.RunFunctionRequest, desired map[resource.Name]*resource.DesiredComposed, rendered map[resource.Name]bool, in *v1beta1.Diff) error {
+	diffs, err := diffDesiredComposed(req, desired, rendered)
+	if err != nil {
+		return err
+	}
+	for _, d := range diffs {
+		if in.Output == v1beta1.LogDiffOutput {
+			log.Info("Desired composed resource changes", "resource", d.Name, "desired", d.Desired, "observed", d.Observed)
+			continue
+		}
+		response.Normal(rsp, d.String())
+	}
+
+	return nil
+}
+
+// normalize returns a copy of the supplied object as decoded from JSON, so
+// that numbers compare equal regardless of how they were decoded.
+func normalize(obj map[string]any) (map[string]any, error) {
+	b, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	out := map[string]any{}
+	err = json.Unmarshal(b, &out)
+
+	return out, err
+}
+
+// diffValues returns the changed fields between before and after, ordered by
+// path. If ignoreRemoved is true, fields that only exist in before are not
+// reported. Values are redacted according to r.
+func diffValues(path string, before, after any, ignoreRemoved bool, r redaction) []string {
+	if reflect.DeepEqual(before, after) {
+		return nil
+	}
+
+	switch a := after.(type) {
+	case map[string]any:
+		b, ok := before.(map[string]any)
+		if !ok {
+			break
+		}
+		keys := make([]string, 0, len(a)+len(b))
+		for k := range a {
+			keys = append(keys, k)
+		}
+		for k := range b {
+			if _, ok := a[k]; !ok && !ignoreRemoved {
+				keys = append(keys, k)
+			}
+		}
+		slices.Sort(keys)
+
+		var changes []string
+		for _, k := range keys {
+			bv, inBefore := b[k]
+			av, inAfter := a[k]
+			p := fieldPath(path, k)
+			fr := r.field(k)
+			switch {
+			case !inBefore:
+				changes = append(changes, fmt.Sprintf("%s: added %s", p, formatDiffValue(av, fr)))
+			case !inAfter:
+				changes = append(changes, fmt.Sprintf("%s: removed %s", p, formatDiffValue(bv, fr)))
+			default:
+				changes = append(changes, diffValues(p, bv, av, ignoreRemoved, fr)...)
+			}
+		}
+
+		return changes
+	case []any:
+		b, ok := before.([]any)
+		if !ok || len(a) != len(b) {
+			break
+		}
+		var changes []string
+		for i := range a {
+			changes = append(changes, diffValues(path+"["+strconv.Itoa(i)+"]", b[i], a[i], ignoreRemoved, r)...)
+		}
+
+		return changes
+	}
+
+	return []string{fmt.Sprintf("%s: %s -> %s", path, formatDiffValue(before, r), formatDiffValue(after, r))}
+}
+
+// formatDiffValue returns the supplied value as truncated JSON, or a
+// placeholder if it's redacted.
+func formatDiffValue(v any, r redaction) string {
+	if r == redactAll {
+		return redactedDiffValue
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	s := string(b)
+	if len(s) > maxDiffValueLength {
+		s = s[:maxDiffValueLength] + "..."
+	}
+
+	return s
+}
@@ -0,0 +1,133 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func Test_diffValues(t *testing.T) {
+	before := map[string]any{
+		"metadata": map[string]any{"name": "logs", "uid": "1234"},
+		"spec": map[string]any{
+			"region": "eu-west-1",
+			"size":   float64(1),
+			"tags":   []any{"a", "b"},
+			"zones":  []any{"a"},
+		},
+		"status": map[string]any{"ready": true},
+	}
+	after := map[string]any{
+		"metadata": map[string]any{"name": "logs", "labels": map[string]any{"team": "platform"}},
+		"spec": map[string]any{
+			"region": "us-east-1",
+			"size":   float64(1),
+			"tags":   []any{"a", "c"},
+			"zones":  []any{"a", "b"},
+		},
+	}
+
+	type args struct {
+		ignoreRemoved bool
+		redaction     redaction
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   []string
+	}{
+		"All": {
+			reason: "Should return added, removed and changed fields ordered by path",
+			want: []string{
+				`metadata.labels: added {"team":"platform"}`,
+				`metadata.uid: removed "1234"`,
+				`spec.region: "eu-west-1" -> "us-east-1"`,
+				`spec.tags[1]: "b" -> "c"`,
+				`spec.zones: ["a"] -> ["a","b"]`,
+				`status: removed {"ready":true}`,
+			},
+		},
+		"IgnoreRemoved": {
+			reason: "Should not return fields that were removed",
+			args:   args{ignoreRemoved: true},
+			want: []string{
+				`metadata.labels: added {"team":"platform"}`,
+				`spec.region: "eu-west-1" -> "us-east-1"`,
+				`spec.tags[1]: "b" -> "c"`,
+				`spec.zones: ["a"] -> ["a","b"]`,
+			},
+		},
+		"RedactAll": {
+			reason: "Should not return any values if all values are redacted",
+			args:   args{ignoreRemoved: true, redaction: redactAll},
+			want: []string{
+				`metadata.labels: added <redacted>`,
+				`spec.region: <redacted> -> <redacted>`,
+				`spec.tags[1]: <redacted> -> <redacted>`,
+				`spec.zones: <redacted> -> <redacted>`,
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := diffValues("", before, after, tc.args.ignoreRemoved, tc.args.redaction)
+			if diff := cmp.Diff(tc.want, got, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("%s\ndiffValues(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func Test_diffValuesRedactData(t *testing.T) {
+	before := map[string]any{
+		"spec": map[string]any{
+			"forProvider": map[string]any{
+				"manifest": map[string]any{
+					"data":       map[string]any{"password": "old"},
+					"stringData": map[string]any{"token": "old"},
+				},
+				"region": "eu-west-1",
+			},
+		},
+	}
+	after := map[string]any{
+		"spec": map[string]any{
+			"forProvider": map[string]any{
+				"manifest": map[string]any{
+					"data":       map[string]any{"password": "new"},
+					"stringData": map[string]any{"token": "new", "user": "admin"},
+				},
+				"region": "us-east-1",
+			},
+		},
+	}
+
+	want := []string{
+		`spec.forProvider.manifest.data.password: <redacted> -> <redacted>`,
+		`spec.forProvider.manifest.stringData.token: <redacted> -> <redacted>`,
+		`spec.forProvider.manifest.stringData.user: added <redacted>`,
+		`spec.forProvider.region: "eu-west-1" -> "us-east-1"`,
+	}
+	if diff := cmp.Diff(want, diffValues("", before, after, false, redactData)); diff != "" {
+		t.Errorf("diffValues(...): -want, +got:\n%s", diff)
+	}
+}
+
+func Test_redactionOf(t *testing.T) {
+	cases := map[string]struct {
+		obj  map[string]any
+		want redaction
+	}{
+		"Secret":    {obj: map[string]any{"apiVersion": "v1", "kind": "Secret"}, want: redactAll},
+		"ConfigMap": {obj: map[string]any{"apiVersion": "v1", "kind": "ConfigMap"}, want: redactData},
+		"Other":     {obj: map[string]any{"apiVersion": "example.org/v1", "kind": "Secret"}, want: redactData},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, redactionOf(tc.obj)); diff != "" {
+				t.Errorf("redactionOf(...): -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
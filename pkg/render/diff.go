@@ -0,0 +1,109 @@
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"github.com/crossplane/function-sdk-go/errors"
+	fnv1 "github.com/crossplane/function-sdk-go/proto/v1"
+)
+
+// A ResponseChange is a change of a part of a response.
+type ResponseChange struct {
+	// Part is one of resources, status, context, conditions or results.
+	Part string
+	// Changes are the changed fields of the part, ordered by path.
+	Changes []string
+}
+
+// DiffResponses returns the changes of the desired composed resources, the
+// composite status, the context, the conditions and the results between two
+// responses.
+func DiffResponses(before, after *fnv1.RunFunctionResponse) ([]ResponseChange, error) {
+	b, err := responseParts(before)
+	if err != nil {
+		return nil, err
+	}
+	a, err := responseParts(after)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []ResponseChange
+	for _, part := range []string{"resources", "status", "context", "conditions", "results"} {
+		bp, _ := b[part].(map[string]any)
+		ap, _ := a[part].(map[string]any)
+		if bp == nil {
+			bp = map[string]any{}
+		}
+		if ap == nil {
+			ap = map[string]any{}
+		}
+		if c := diffValues("", bp, ap, false, redactNone); len(c) > 0 {
+			changes = append(changes, ResponseChange{Part: part, Changes: c})
+		}
+	}
+
+	return changes, nil
+}
+
+// responseParts returns the compared parts of the response as JSON values.
+// Conditions are keyed by type and results by index, so that their changes
+// have readable paths.
+func responseParts(rsp *fnv1.RunFunctionResponse) (map[string]any, error) {
+	j, err := protojson.Marshal(rsp)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot marshal response")
+	}
+	m := map[string]any{}
+	if err := json.Unmarshal(j, &m); err != nil {
+		return nil, errors.Wrap(err, "cannot unmarshal response")
+	}
+
+	parts := map[string]any{}
+	desired, _ := m["desired"].(map[string]any)
+	if res, ok := desired["resources"].(map[string]any); ok {
+		resources := map[string]any{}
+		for name, r := range res {
+			if r, ok := r.(map[string]any); ok {
+				resources[name] = r["resource"]
+			}
+		}
+		parts["resources"] = resources
+	}
+	if c, ok := desired["composite"].(map[string]any); ok {
+		if r, ok := c["resource"].(map[string]any); ok {
+			parts["status"], _ = r["status"].(map[string]any)
+		}
+	}
+	parts["context"], _ = m["context"].(map[string]any)
+
+	conditions := map[string]any{}
+	for _, c := range listOf(m["conditions"]) {
+		t, _ := c["type"].(string)
+		conditions[t] = c
+	}
+	parts["conditions"] = conditions
+
+	results := map[string]any{}
+	for i, r := range listOf(m["results"]) {
+		results[fmt.Sprint(i)] = r
+	}
+	parts["results"] = results
+
+	return parts, nil
+}
+
+func listOf(v any) []map[string]any {
+	l, _ := v.([]any)
+	out := make([]map[string]any, 0, len(l))
+	for _, e := range l {
+		if m, ok := e.(map[string]any); ok {
+			out = append(out, m)
+		}
+	}
+
+	return out
+}
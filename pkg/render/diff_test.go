@@ -0,0 +1,74 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	fnv1 "github.com/crossplane/function-sdk-go/proto/v1"
+	"github.com/crossplane/function-sdk-go/resource"
+)
+
+func TestDiffResponses(t *testing.T) {
+	before := &fnv1.RunFunctionResponse{
+		Desired: &fnv1.State{
+			Composite: &fnv1.Resource{Resource: resource.MustStructJSON(`{"status":{"region":"eu-west-1"}}`)},
+			Resources: map[string]*fnv1.Resource{
+				"bucket":     {Resource: resource.MustStructJSON(`{"apiVersion":"example.org/v1","kind":"Bucket","spec":{"region":"eu-west-1"}}`)},
+				"old-bucket": {Resource: resource.MustStructJSON(`{"apiVersion":"example.org/v1","kind":"Bucket"}`)},
+			},
+		},
+		Conditions: []*fnv1.Condition{{Type: "Ready", Status: fnv1.Status_STATUS_CONDITION_FALSE}},
+	}
+	after := &fnv1.RunFunctionResponse{
+		Desired: &fnv1.State{
+			Composite: &fnv1.Resource{Resource: resource.MustStructJSON(`{"status":{"region":"us-east-1"}}`)},
+			Resources: map[string]*fnv1.Resource{
+				"bucket": {Resource: resource.MustStructJSON(`{"apiVersion":"example.org/v1","kind":"Bucket","spec":{"region":"us-east-1"}}`)},
+			},
+		},
+		Context:    resource.MustStructJSON(`{"example.org/key":"value"}`),
+		Conditions: []*fnv1.Condition{{Type: "Ready", Status: fnv1.Status_STATUS_CONDITION_TRUE}},
+		Results:    []*fnv1.Result{{Severity: fnv1.Severity_SEVERITY_FATAL, Message: "boom"}},
+	}
+
+	cases := map[string]struct {
+		reason string
+		before *fnv1.RunFunctionResponse
+		after  *fnv1.RunFunctionResponse
+		want   []ResponseChange
+	}{
+		"NoChanges": {
+			reason: "Should return no changes for equal responses",
+			before: before,
+			after:  before,
+		},
+		"Changes": {
+			reason: "Should return the changes of each part of the response",
+			before: before,
+			after:  after,
+			want: []ResponseChange{
+				{Part: "resources", Changes: []string{
+					`bucket.spec.region: "eu-west-1" -> "us-east-1"`,
+					`old-bucket: removed {"apiVersion":"example.org/v1","kind":"Bucket"}`,
+				}},
+				{Part: "status", Changes: []string{`region: "eu-west-1" -> "us-east-1"`}},
+				{Part: "context", Changes: []string{`example.org/key: added "value"`}},
+				{Part: "conditions", Changes: []string{`Ready.status: "STATUS_CONDITION_FALSE" -> "STATUS_CONDITION_TRUE"`}},
+				{Part: "results", Changes: []string{`0: added {"message":"boom","severity":"SEVERITY_FATAL"}`}},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := DiffResponses(tc.before, tc.after)
+			if err != nil {
+				t.Fatalf("DiffResponses(...): %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("%s\nDiffResponses(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
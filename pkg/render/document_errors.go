@@ -0,0 +1,183 @@
+package render
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/crossplane-contrib/function-go-templating/input/v1beta1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/crossplane/function-sdk-go/errors"
+	fnv1 "github.com/crossplane/function-sdk-go/proto/v1"
+	"github.com/crossplane/function-sdk-go/resource"
+	"github.com/crossplane/function-sdk-go/response"
+)
+
+// A documentError is a problem with a rendered document.
+type documentError struct {
+	// Document is the index of the document in the rendered manifests,
+	// starting at 1, or 0 if the template could not be rendered.
+	Document int
+	Kind     string
+	Name     string
+	// Resources are the composition resource names of the document. They
+	// are unknown if empty.
+	Resources []string
+	Err       error
+}
+
+// Error returns the problem, prefixed by the document and resource it
+// occurred in.
+func (e *documentError) Error() string {
+	switch {
+	case e.Document == 0:
+		// The problem occurred before the documents were rendered.
+		return e.Err.Error()
+	case e.Kind == "" && e.Name == "":
+		return fmt.Sprintf("document %d: %s", e.Document, e.Err)
+	case e.Name == "":
+		return fmt.Sprintf("document %d (%s): %s", e.Document, e.Kind, e.Err)
+	default:
+		return fmt.Sprintf("document %d (%s %q): %s", e.Document, e.Kind, e.Name, e.Err)
+	}
+}
+
+// Unwrap returns the underlying problem.
+func (e *documentError) Unwrap() error {
+	return e.Err
+}
+
+// newDocumentError returns a problem with the document at the supplied index,
+// starting at 0. The resource is identified by its composition resource name,
+// or by its metadata.name if it has none. obj may be nil.
+func newDocumentError(index int, obj *unstructured.Unstructured, err error) *documentError {
+	e := &documentError{Document: index + 1, Err: err}
+	if obj == nil {
+		return e
+	}
+	e.Kind = obj.GetKind()
+	e.Name = obj.GetName()
+	if n, ok := obj.GetAnnotations()[annotationKeyCompositionResourceName]; ok {
+		e.Name = n
+		e.Resources = []string{n}
+	}
+
+	return e
+}
+
+// documentErrors reports problems with rendered documents according to the
+// error mode and failure policy.
+type documentErrors struct {
+	aggregate bool
+	skip      bool
+	errs      []*documentError
+}
+
+func newDocumentErrors(mode v1beta1.ErrorMode, policy v1beta1.FailurePolicy) *documentErrors {
+	return &documentErrors{
+		aggregate: mode == v1beta1.AggregateErrorMode,
+		skip:      policy == v1beta1.SkipDocumentFailurePolicy,
+	}
+}
+
+// PerDocument reports whether problems are reported for each document, rather
+// than at once.
+func (e *documentErrors) PerDocument() bool {
+	return e.aggregate || e.skip
+}
+
+// Fail reports a problem with a document. In FailFast mode it returns a fatal
+// result and true, and the function should stop. In Aggregate mode it records
+// the problem and returns false, and the function should skip the document.
+// With the SkipDocument failure policy it returns a warning result and false.
+func (e *documentErrors) Fail(rsp *fnv1.RunFunctionResponse, err *documentError) bool {
+	switch {
+	case e.skip:
+		response.Warning(rsp, errors.Wrap(err, "skipping invalid document"))
+	case !e.aggregate:
+		response.Fatal(rsp, err.Err)
+		return true
+	}
+	e.errs = append(e.errs, err)
+
+	return false
+}
+
+// Skipped returns the composition resource names of the skipped documents.
+// It returns true if the names of any skipped document are unknown, e.g.
+// because the name is set by a function, in which case any observed composed
+// resource could be one of the skipped documents.
+func (e *documentErrors) Skipped() ([]string, bool) {
+	if !e.skip {
+		return nil, false
+	}
+	var names []string
+	unknown := false
+	for _, err := range e.errs {
+		if len(err.Resources) == 0 {
+			unknown = true
+		}
+		names = append(names, err.Resources...)
+	}
+
+	return names, unknown
+}
+
+// Report returns a warning result for each recorded problem, ordered by
+// document, and a fatal result with all of them. It returns true if problems
+// were recorded.
+func (e *documentErrors) Report(rsp *fnv1.RunFunctionResponse) bool {
+	// Skipped documents were already reported.
+	if e.skip || len(e.errs) == 0 {
+		return false
+	}
+
+	// Documents are decoded before they are processed, so decode errors are
+	// recorded first.
+	slices.SortStableFunc(e.errs, func(a, b *documentError) int { return a.Document - b.Document })
+
+	msgs := make([]string, len(e.errs))
+	for i, err := range e.errs {
+		response.Warning(rsp, err)
+		msgs[i] = err.Error()
+	}
+	response.Fatal(rsp, errors.Errorf("cannot compose resources, found %d problems in rendered documents: %s", len(e.errs), strings.Join(msgs, "; ")))
+
+	return true
+}
+
+// resourceNameAnnotation matches the composition resource name annotations of
+// a document that can't be decoded.
+var resourceNameAnnotation = regexp.MustCompile(`(?m)^\s*["']?` + regexp.QuoteMeta(annotationKeyCompositionResourceName) + `["']?\s*:\s*["']?([^"'\s#]+)["']?\s*(#.*)?$`)
+
+// resourceNamesOf returns the composition resource name annotations of a raw
+// document or template.
+func resourceNamesOf(doc string) []string {
+	var names []string
+	for _, m := range resourceNameAnnotation.FindAllStringSubmatch(doc, -1) {
+		names = append(names, m[1])
+	}
+
+	return names
+}
+
+// keepObserved returns the observed composed resource as desired composed
+// resource, without its status and the metadata set by the API server.
+func keepObserved(oc resource.ObservedComposed) *resource.DesiredComposed {
+	cd := resource.NewDesiredComposed()
+	cd.Resource = oc.Resource.DeepCopy()
+	for _, f := range [][]string{
+		{"status"},
+		{"metadata", "creationTimestamp"},
+		{"metadata", "generation"},
+		{"metadata", "managedFields"},
+		{"metadata", "resourceVersion"},
+		{"metadata", "uid"},
+	} {
+		unstructured.RemoveNestedField(cd.Resource.Object, f...)
+	}
+
+	return cd
+}
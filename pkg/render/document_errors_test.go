@@ -0,0 +1,142 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/crossplane-contrib/function-go-templating/input/v1beta1"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/crossplane/function-sdk-go/errors"
+	fnv1 "github.com/crossplane/function-sdk-go/proto/v1"
+)
+
+func Test_documentError(t *testing.T) {
+	bucket := &unstructured.Unstructured{Object: map[string]any{
+		"kind":     "Bucket",
+		"metadata": map[string]any{"name": "cool-bucket"},
+	}}
+	named := bucket.DeepCopy()
+	named.SetAnnotations(map[string]string{annotationKeyCompositionResourceName: "bucket"})
+	boom := errors.New("boom")
+
+	cases := map[string]struct {
+		reason string
+		err    *documentError
+		want   string
+	}{
+		"NoResource": {
+			reason: "Should only include the document if the resource is unknown",
+			err:    newDocumentError(0, nil, boom),
+			want:   "document 1: boom",
+		},
+		"KindOnly": {
+			reason: "Should include the kind of resources without a name",
+			err:    newDocumentError(1, &unstructured.Unstructured{Object: map[string]any{"kind": "Context"}}, boom),
+			want:   "document 2 (Context): boom",
+		},
+		"Name": {
+			reason: "Should include the name of resources without a resource name annotation",
+			err:    newDocumentError(2, bucket, boom),
+			want:   `document 3 (Bucket "cool-bucket"): boom`,
+		},
+		"ResourceName": {
+			reason: "Should prefer the composition resource name",
+			err:    newDocumentError(3, named, boom),
+			want:   `document 4 (Bucket "bucket"): boom`,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, tc.err.Error()); diff != "" {
+				t.Errorf("%s\nError(): -want, +got:\n%s", tc.reason, diff)
+			}
+			if !errors.Is(tc.err, boom) {
+				t.Errorf("%s\nerrors.Is(...): want the underlying error", tc.reason)
+			}
+		})
+	}
+}
+
+func Test_documentErrors(t *testing.T) {
+	boom := errors.New("boom")
+
+	t.Run("FailFast", func(t *testing.T) {
+		rsp := &fnv1.RunFunctionResponse{}
+		e := newDocumentErrors(v1beta1.FailFastErrorMode, v1beta1.FatalFailurePolicy)
+		if !e.Fail(rsp, newDocumentError(0, nil, boom)) {
+			t.Errorf("Fail(...): want true in FailFast mode")
+		}
+		want := []*fnv1.Result{{Severity: fnv1.Severity_SEVERITY_FATAL, Message: "boom", Target: fnv1.Target_TARGET_COMPOSITE.Enum()}}
+		if diff := cmp.Diff(want, rsp.GetResults(), protocmp.Transform()); diff != "" {
+			t.Errorf("Fail(...): -want, +got:\n%s", diff)
+		}
+	})
+
+	t.Run("Aggregate", func(t *testing.T) {
+		rsp := &fnv1.RunFunctionResponse{}
+		e := newDocumentErrors(v1beta1.AggregateErrorMode, v1beta1.FatalFailurePolicy)
+		if e.Report(rsp) {
+			t.Errorf("Report(...): want false without problems")
+		}
+		if e.Fail(rsp, newDocumentError(1, nil, boom)) || e.Fail(rsp, newDocumentError(0, nil, boom)) {
+			t.Errorf("Fail(...): want false in Aggregate mode")
+		}
+		if !e.Report(rsp) {
+			t.Errorf("Report(...): want true with problems")
+		}
+		want := []*fnv1.Result{
+			{Severity: fnv1.Severity_SEVERITY_WARNING, Message: "document 1: boom", Target: fnv1.Target_TARGET_COMPOSITE.Enum()},
+			{Severity: fnv1.Severity_SEVERITY_WARNING, Message: "document 2: boom", Target: fnv1.Target_TARGET_COMPOSITE.Enum()},
+			{Severity: fnv1.Severity_SEVERITY_FATAL, Message: "cannot compose resources, found 2 problems in rendered documents: document 1: boom; document 2: boom", Target: fnv1.Target_TARGET_COMPOSITE.Enum()},
+		}
+		if diff := cmp.Diff(want, rsp.GetResults(), protocmp.Transform()); diff != "" {
+			t.Errorf("Report(...): -want, +got:\n%s", diff)
+		}
+	})
+
+	t.Run("SkipDocument", func(t *testing.T) {
+		rsp := &fnv1.RunFunctionResponse{}
+		e := newDocumentErrors(v1beta1.AggregateErrorMode, v1beta1.SkipDocumentFailurePolicy)
+		if e.Fail(rsp, &documentError{Document: 1, Resources: []string{"bucket"}, Err: boom}) || e.Fail(rsp, newDocumentError(1, nil, boom)) {
+			t.Errorf("Fail(...): want false with the SkipDocument failure policy")
+		}
+		if e.Report(rsp) {
+			t.Errorf("Report(...): want false with the SkipDocument failure policy")
+		}
+		names, unknown := e.Skipped()
+		if diff := cmp.Diff([]string{"bucket"}, names); diff != "" {
+			t.Errorf("Skipped(): -want names, +got names:\n%s", diff)
+		}
+		if !unknown {
+			t.Errorf("Skipped(): want unknown names for the document without composition resource name")
+		}
+		want := []*fnv1.Result{
+			{Severity: fnv1.Severity_SEVERITY_WARNING, Message: "skipping invalid document: document 1: boom", Target: fnv1.Target_TARGET_COMPOSITE.Enum()},
+			{Severity: fnv1.Severity_SEVERITY_WARNING, Message: "skipping invalid document: document 2: boom", Target: fnv1.Target_TARGET_COMPOSITE.Enum()},
+		}
+		if diff := cmp.Diff(want, rsp.GetResults(), protocmp.Transform()); diff != "" {
+			t.Errorf("Fail(...): -want, +got:\n%s", diff)
+		}
+	})
+}
+
+func Test_resourceNamesOf(t *testing.T) {
+	cases := map[string]struct {
+		doc  string
+		want []string
+	}{
+		"Plain":    {doc: "metadata:\n  annotations:\n    gotemplating.fn.crossplane.io/composition-resource-name: bucket\n  name: [broken", want: []string{"bucket"}},
+		"Quoted":   {doc: "metadata:\n  annotations:\n    \"gotemplating.fn.crossplane.io/composition-resource-name\": 'bucket' # comment\n", want: []string{"bucket"}},
+		"Multiple": {doc: "gotemplating.fn.crossplane.io/composition-resource-name: a\n---\ngotemplating.fn.crossplane.io/composition-resource-name: b\n", want: []string{"a", "b"}},
+		"Missing":  {doc: "metadata:\n  name: bucket\n"},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, resourceNamesOf(tc.doc)); diff != "" {
+				t.Errorf("resourceNamesOf(...): -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
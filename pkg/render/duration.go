@@ -0,0 +1,207 @@
+package render
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/crossplane/function-sdk-go/errors"
+)
+
+// iso8601Duration matches ISO 8601 durations with weeks, days, hours, minutes
+// and seconds. Years and months are not supported because their length
+// varies.
+var iso8601Duration = regexp.MustCompile(`^(-)?P(?:(\d+(?:\.\d+)?)W)?(?:(\d+(?:\.\d+)?)D)?(?:T(?:(\d+(?:\.\d+)?)H)?(?:(\d+(?:\.\d+)?)M)?(?:(\d+(?:\.\d+)?)S)?)?$`)
+
+// toDuration parses v as a Go duration like "1h30m" or an ISO 8601 duration
+// like "PT1H30M". Numbers are interpreted as seconds.
+func toDuration(v any) (time.Duration, error) {
+	switch t := v.(type) {
+	case time.Duration:
+		return t, nil
+	case int:
+		return time.Duration(t) * time.Second, nil
+	case int64:
+		return time.Duration(t) * time.Second, nil
+	case float64:
+		return time.Duration(t * float64(time.Second)), nil
+	case string:
+		if strings.HasPrefix(strings.TrimPrefix(t, "-"), "P") {
+			return parseISO8601Duration(t)
+		}
+		d, err := time.ParseDuration(t)
+		if err != nil {
+			return 0, errors.Wrapf(err, "cannot parse %q as a duration", t)
+		}
+		return d, nil
+	default:
+		return 0, errors.Errorf("cannot parse %v (%T) as a duration", v, v)
+	}
+}
+
+func parseISO8601Duration(s string) (time.Duration, error) {
+	m := iso8601Duration.FindStringSubmatch(s)
+	if m == nil || s == "P" || s == "-P" || strings.HasSuffix(s, "T") {
+		return 0, errors.Errorf("cannot parse %q as an ISO 8601 duration: must be of the form PnWnDTnHnMnS", s)
+	}
+
+	units := []time.Duration{7 * 24 * time.Hour, 24 * time.Hour, time.Hour, time.Minute, time.Second}
+	var d float64
+	for i, u := range units {
+		if m[i+2] == "" {
+			continue
+		}
+		n, err := strconv.ParseFloat(m[i+2], 64)
+		if err != nil {
+			return 0, errors.Wrapf(err, "cannot parse %q as an ISO 8601 duration", s)
+		}
+		d += n * float64(u)
+	}
+	if d > math.MaxInt64 {
+		return 0, errors.Errorf("ISO 8601 duration %q is too long", s)
+	}
+	if m[1] == "-" {
+		d = -d
+	}
+
+	return time.Duration(d), nil
+}
+
+// durationAdd returns the sum of the supplied durations as a Go duration.
+func durationAdd(first any, rest ...any) (string, error) {
+	sum, err := toDuration(first)
+	if err != nil {
+		return "", err
+	}
+	for _, v := range rest {
+		d, err := toDuration(v)
+		if err != nil {
+			return "", err
+		}
+		sum += d
+	}
+
+	return sum.String(), nil
+}
+
+// durationSub returns a minus b as a Go duration.
+func durationSub(a, b any) (string, error) {
+	da, err := toDuration(a)
+	if err != nil {
+		return "", err
+	}
+	db, err := toDuration(b)
+	if err != nil {
+		return "", err
+	}
+
+	return (da - db).String(), nil
+}
+
+// durationMul returns d multiplied by factor as a Go duration.
+func durationMul(d, factor any) (string, error) {
+	dd, err := toDuration(d)
+	if err != nil {
+		return "", err
+	}
+	f, err := toFloat64(factor)
+	if err != nil {
+		return "", errors.Wrap(err, "invalid factor")
+	}
+
+	return time.Duration(float64(dd) * f).String(), nil
+}
+
+// durationCmp returns -1, 0 or 1 if a is shorter than, equal to or longer
+// than b.
+func durationCmp(a, b any) (int, error) {
+	da, err := toDuration(a)
+	if err != nil {
+		return 0, err
+	}
+	db, err := toDuration(b)
+	if err != nil {
+		return 0, err
+	}
+
+	switch {
+	case da < db:
+		return -1, nil
+	case da > db:
+		return 1, nil
+	default:
+		return 0, nil
+	}
+}
+
+// durationSeconds returns d in seconds. Whole seconds are returned as an
+// integer.
+func durationSeconds(d any) (any, error) {
+	dd, err := toDuration(d)
+	if err != nil {
+		return 0, err
+	}
+
+	return toNumber(dd.Seconds()), nil
+}
+
+// durationToISO8601 returns d as an ISO 8601 duration like "PT1H30M".
+func durationToISO8601(d any) (string, error) {
+	dd, err := toDuration(d)
+	if err != nil {
+		return "", err
+	}
+	if dd == 0 {
+		return "PT0S", nil
+	}
+
+	var b strings.Builder
+	if dd < 0 {
+		b.WriteString("-")
+		dd = -dd
+	}
+	b.WriteString("P")
+	if days := dd / (24 * time.Hour); days > 0 {
+		b.WriteString(strconv.FormatInt(int64(days), 10) + "D")
+		dd -= days * 24 * time.Hour
+	}
+	if dd > 0 {
+		b.WriteString("T")
+	}
+	if h := dd / time.Hour; h > 0 {
+		b.WriteString(strconv.FormatInt(int64(h), 10) + "H")
+		dd -= h * time.Hour
+	}
+	if m := dd / time.Minute; m > 0 {
+		b.WriteString(strconv.FormatInt(int64(m), 10) + "M")
+		dd -= m * time.Minute
+	}
+	if dd > 0 {
+		b.WriteString(strconv.FormatFloat(dd.Seconds(), 'f', -1, 64) + "S")
+	}
+
+	return b.String(), nil
+}
+
+// toFloat64 converts the numeric types that commonly appear in templates to a
+// float64.
+func toFloat64(v any) (float64, error) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), nil
+	case int64:
+		return float64(n), nil
+	case float64:
+		return n, nil
+	case string:
+		f, err := strconv.ParseFloat(n, 64)
+		if err != nil {
+			return 0, errors.Wrapf(err, "cannot parse %q as a number", n)
+		}
+		return f, nil
+	default:
+		return 0, errors.Errorf("%v (%T) is not a number", v, v)
+	}
+}
@@ -0,0 +1,149 @@
+package render
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func Test_toDuration(t *testing.T) {
+	type want struct {
+		rsp time.Duration
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		val    any
+		want   want
+	}{
+		"Go": {
+			reason: "Should parse Go durations",
+			val:    "1h30m",
+			want:   want{rsp: 90 * time.Minute},
+		},
+		"ISO8601": {
+			reason: "Should parse ISO 8601 durations",
+			val:    "P1W2DT3H4M5.5S",
+			want:   want{rsp: 9*24*time.Hour + 3*time.Hour + 4*time.Minute + 5500*time.Millisecond},
+		},
+		"ISO8601Negative": {
+			reason: "Should parse negative ISO 8601 durations",
+			val:    "-PT15M",
+			want:   want{rsp: -15 * time.Minute},
+		},
+		"ISO8601Months": {
+			reason: "Should return an error for ISO 8601 durations with months",
+			val:    "P1M",
+			want:   want{err: cmpopts.AnyError},
+		},
+		"ISO8601Empty": {
+			reason: "Should return an error for ISO 8601 durations without components",
+			val:    "PT",
+			want:   want{err: cmpopts.AnyError},
+		},
+		"Seconds": {
+			reason: "Should interpret numbers as seconds",
+			val:    float64(90),
+			want:   want{rsp: 90 * time.Second},
+		},
+		"Invalid": {
+			reason: "Should return an error for invalid durations",
+			val:    "one hour",
+			want:   want{err: cmpopts.AnyError},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			rsp, err := toDuration(tc.val)
+			if diff := cmp.Diff(tc.want.rsp, rsp); diff != "" {
+				t.Errorf("%s\ntoDuration(...): -want rsp, +got rsp:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("%s\ntoDuration(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func Test_durationArithmetic(t *testing.T) {
+	type want struct {
+		rsp any
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		fn     func() (any, error)
+		want   want
+	}{
+		"Add": {
+			reason: "Should add Go and ISO 8601 durations",
+			fn:     func() (any, error) { return durationAdd("1h", "PT30M", 15) },
+			want:   want{rsp: "1h30m15s"},
+		},
+		"Sub": {
+			reason: "Should subtract durations",
+			fn:     func() (any, error) { return durationSub("P1D", "1h") },
+			want:   want{rsp: "23h0m0s"},
+		},
+		"Mul": {
+			reason: "Should multiply a duration by a factor",
+			fn:     func() (any, error) { return durationMul("90s", 1.5) },
+			want:   want{rsp: "2m15s"},
+		},
+		"MulInvalidFactor": {
+			reason: "Should return an error if the factor is invalid",
+			fn:     func() (any, error) { return durationMul("90s", "twice") },
+			want:   want{err: cmpopts.AnyError},
+		},
+		"Cmp": {
+			reason: "Should compare durations",
+			fn:     func() (any, error) { return durationCmp("PT60M", "1h") },
+			want:   want{rsp: 0},
+		},
+		"Seconds": {
+			reason: "Should return a duration in seconds",
+			fn:     func() (any, error) { return durationSeconds("PT1M30S") },
+			want:   want{rsp: int64(90)},
+		},
+		"SecondsLarge": {
+			reason: "Should return large whole durations as integers",
+			fn:     func() (any, error) { return durationSeconds("720h") },
+			want:   want{rsp: int64(2592000)},
+		},
+		"SecondsFraction": {
+			reason: "Should return fractions of seconds",
+			fn:     func() (any, error) { return durationSeconds("1500ms") },
+			want:   want{rsp: 1.5},
+		},
+		"ToISO8601": {
+			reason: "Should convert a duration to ISO 8601",
+			fn:     func() (any, error) { return durationToISO8601("26h3m4.5s") },
+			want:   want{rsp: "P1DT2H3M4.5S"},
+		},
+		"ToISO8601Zero": {
+			reason: "Should convert a zero duration to ISO 8601",
+			fn:     func() (any, error) { return durationToISO8601("0s") },
+			want:   want{rsp: "PT0S"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			rsp, err := tc.fn()
+			if tc.want.err != nil {
+				rsp = nil
+			}
+			if diff := cmp.Diff(tc.want.rsp, rsp); diff != "" {
+				t.Errorf("%s\n-want rsp, +got rsp:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("%s\n-want err, +got err:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
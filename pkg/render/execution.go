@@ -0,0 +1,57 @@
+package render
+
+import (
+	"bytes"
+	"strings"
+	"text/template"
+
+	"github.com/crossplane/function-sdk-go/errors"
+	fnv1 "github.com/crossplane/function-sdk-go/proto/v1"
+)
+
+// executeTemplateEntries parses and executes each template entry on its own,
+// and returns the rendered manifests of the entries that succeeded and their
+// documents. Named templates defined by any entry are available to all of
+// them. Problems with an entry are reported to docErrs, and the function
+// should stop if it returns true.
+func executeTemplateEntries(rsp *fnv1.RunFunctionResponse, tmpl *template.Template, entries []TemplateEntry, reqMap map[string]any, docErrs *documentErrors) (string, []renderedDocument, bool) {
+	// Parse all entries before executing any of them, so that entries can use
+	// templates defined by later entries.
+	parsed := make([]TemplateEntry, 0, len(entries))
+	for _, e := range entries {
+		if _, err := tmpl.New(e.Name).Parse(e.Template); err != nil {
+			if docErrs.Fail(rsp, newTemplateError(errors.Wrapf(err, "cannot parse template %s", e.Name))) {
+				return "", nil, true
+			}
+			continue
+		}
+		parsed = append(parsed, e)
+	}
+
+	out := &strings.Builder{}
+	var docs []renderedDocument
+	for _, e := range parsed {
+		buf := &bytes.Buffer{}
+		if err := tmpl.ExecuteTemplate(buf, e.Name, reqMap); err != nil {
+			if docErrs.Fail(rsp, newTemplateError(errors.Wrapf(err, "cannot execute template %s", e.Name))) {
+				return "", nil, true
+			}
+			continue
+		}
+		buf.WriteString("\n---\n")
+		out.Write(buf.Bytes())
+		// The separator ends the entry's last document, so its documents
+		// are the same whether read on their own or as part of out.
+		docs = append(docs, documentsOf(buf.String(), e.File)...)
+	}
+
+	return out.String(), docs, false
+}
+
+// newTemplateError returns a problem with a template entry. The composition
+// resource names of the entry's documents are unknown: they may be templated,
+// set by setResourceNameAnnotation or derived by the resource naming policy,
+// and the entry may render a varying number of documents.
+func newTemplateError(err error) *documentError {
+	return &documentError{Err: err}
+}
@@ -0,0 +1,69 @@
+package render
+
+import (
+	"maps"
+	"slices"
+	"sync"
+	"text/template"
+
+	"github.com/crossplane/function-sdk-go/errors"
+)
+
+var (
+	extensionsMu sync.RWMutex
+	extensions   = make(map[string]template.FuncMap)
+)
+
+// RegisterFunctions registers the template functions of an extension module,
+// e.g. from the init function of a package that is imported by a custom build
+// of the function. The functions are available to all templates rendered
+// after they are registered, and take precedence over Sprig's and the
+// built-in functions, including include and tpl. They don't replace the
+// credential functions and the functions of WithFunctions, which the Renderer
+// adds last. It panics if a module with the same name is already registered.
+func RegisterFunctions(module string, funcs template.FuncMap) {
+	if err := registerModules(map[string]template.FuncMap{module: funcs}); err != nil {
+		panic("render: RegisterFunctions: " + err.Error())
+	}
+}
+
+// registerModules registers the template functions of the supplied modules,
+// by module name. It returns an error and registers none of them if a module
+// with the same name is already registered.
+func registerModules(modules map[string]template.FuncMap) error {
+	extensionsMu.Lock()
+	defer extensionsMu.Unlock()
+
+	for _, m := range slices.Sorted(maps.Keys(modules)) {
+		if _, ok := extensions[m]; ok {
+			return errors.Errorf("module %s is already registered", m)
+		}
+	}
+	for m, funcs := range modules {
+		extensions[m] = maps.Clone(funcs)
+	}
+
+	return nil
+}
+
+// RegisteredModules returns the names of the registered extension modules.
+func RegisteredModules() []string {
+	extensionsMu.RLock()
+	defer extensionsMu.RUnlock()
+
+	return slices.Sorted(maps.Keys(extensions))
+}
+
+// registeredFunctions returns the function maps of the registered extension
+// modules, ordered by module name.
+func registeredFunctions() []template.FuncMap {
+	extensionsMu.RLock()
+	defer extensionsMu.RUnlock()
+
+	funcs := make([]template.FuncMap, 0, len(extensions))
+	for _, m := range slices.Sorted(maps.Keys(extensions)) {
+		funcs = append(funcs, extensions[m])
+	}
+
+	return funcs
+}
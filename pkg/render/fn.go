@@ -1,4 +1,4 @@
-package main
+package render
 
 import (
 	"bytes"
@@ -9,6 +9,7 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"text/template"
 	"time"
@@ -25,7 +26,6 @@ import (
 	"k8s.io/apimachinery/pkg/util/yaml"
 
 	"github.com/crossplane/function-sdk-go/errors"
-	"github.com/crossplane/function-sdk-go/logging"
 	fnv1 "github.com/crossplane/function-sdk-go/proto/v1"
 	"github.com/crossplane/function-sdk-go/request"
 	"github.com/crossplane/function-sdk-go/resource"
@@ -40,17 +40,6 @@ func (*osFS) Open(name string) (fs.File, error) {
 	return os.Open(filepath.Clean(name))
 }
 
-// Function uses Go templates to compose resources.
-type Function struct {
-	fnv1.UnimplementedFunctionRunnerServiceServer
-
-	log            logging.Logger
-	fsys           fs.FS
-	ttl            time.Duration
-	defaultSource  string
-	defaultOptions string
-}
-
 type YamlErrorContext struct {
 	RelLine int
 	AbsLine int
@@ -62,12 +51,14 @@ const (
 	annotationKeyCompositionResourceName = "gotemplating.fn.crossplane.io/composition-resource-name"
 	annotationKeyReady                   = "gotemplating.fn.crossplane.io/ready"
 	annotationKeyTTL                     = "gotemplating.fn.crossplane.io/ttl"
+	annotationKeyClusterScoped           = "gotemplating.fn.crossplane.io/cluster-scoped"
 
 	metaAPIVersion = "meta.gotemplating.fn.crossplane.io/v1alpha1"
 )
 
-// RunFunction runs the Function.
-func (f *Function) RunFunction(_ context.Context, req *fnv1.RunFunctionRequest) (*fnv1.RunFunctionResponse, error) { //nolint:gocognit // this function needs to be refactored
+// Render renders the templates of the request's input and returns the
+// response of the function.
+func (f *Renderer) Render(_ context.Context, req *fnv1.RunFunctionRequest) (*fnv1.RunFunctionResponse, error) { //nolint:gocognit // this function needs to be refactored
 	f.log.Debug("Running Function", "tag", req.GetMeta().GetTag())
 	in := &v1beta1.GoTemplate{}
 
@@ -91,19 +82,60 @@ func (f *Function) RunFunction(_ context.Context, req *fnv1.RunFunctionRequest)
 		}
 	}
 
-	tg, err := NewTemplateSourceGetter(f.fsys, req.GetContext(), in)
+	tg, err := f.templateSourceGetter(req.GetContext(), in)
 	if err != nil {
 		response.Fatal(rsp, errors.Wrap(err, "invalid function input"))
 		return rsp, nil
 	}
 
-	f.log.Debug("template", "template", tg.GetTemplates())
+	reqMap, err := convertToMap(req)
+	if err != nil {
+		response.Fatal(rsp, errors.Wrap(err, "cannot convert request to map"))
+		return rsp, nil
+	}
 
-	tmpl, err := GetNewTemplateWithFunctionMaps(in.Delims).Parse(tg.GetTemplates())
+	// Operations have no composite resource. The mode of the input takes
+	// precedence over what the request looks like.
+	operation := isOperation(in.Mode, reqMap)
+
+	// Decode the function credentials once, rather than on every call of a
+	// credential function.
+	creds, err := decodeCredentials(reqMap)
 	if err != nil {
-		response.Fatal(rsp, errors.Wrap(err, "invalid function input: cannot parse the provided templates"))
+		response.Fatal(rsp, errors.Wrap(err, "cannot decode function credentials"))
 		return rsp, nil
 	}
+	funcs := append([]template.FuncMap{
+		creds.functions(),
+		{"isOperation": func(map[string]any) bool { return operation }},
+	}, f.funcs...)
+
+	namer, err := newResourceNamer(in.ResourceNaming, in.Delims, funcs...)
+	if err != nil {
+		response.Fatal(rsp, errors.Wrap(err, "invalid function input"))
+		return rsp, nil
+	}
+
+	f.log.Debug("template", "template", tg.GetTemplates())
+
+	// Report problems with individual documents according to the error mode.
+	docErrs := newDocumentErrors(in.ErrorMode, in.FailurePolicy)
+
+	// Execute each entry on its own in PerDocument mode, and if derived names
+	// depend on the template file of a resource. File boundaries are tracked
+	// outside the template text, so that the templates render as they are.
+	entries := tg.GetTemplateEntries()
+	perEntry := in.ExecutionMode == v1beta1.PerDocumentExecutionMode ||
+		(namer.UsesFiles() && slices.ContainsFunc(entries, func(e TemplateEntry) bool { return e.File != "" }))
+
+	tmpl := GetNewTemplateWithFunctionMaps(in.Delims, funcs...)
+	if !perEntry {
+		tmpl, err = tmpl.Parse(tg.GetTemplates())
+		if err != nil {
+			response.Fatal(rsp, errors.Wrap(err, "invalid function input: cannot parse the provided templates"))
+			return rsp, nil
+		}
+	}
 
 	if in.Options != nil || f.defaultOptions != "" {
 		var o []string
@@ -120,33 +152,38 @@ func (f *Function) RunFunction(_ context.Context, req *fnv1.RunFunctionRequest)
 		}
 	}
 
-	reqMap, err := convertToMap(req)
-	if err != nil {
-		response.Fatal(rsp, errors.Wrap(err, "cannot convert request to map"))
-		return rsp, nil
-	}
-
 	f.log.Debug("constructed request map", "request", reqMap)
 
-	buf := &bytes.Buffer{}
-
-	if err := tmpl.Execute(buf, reqMap); err != nil {
-		response.Fatal(rsp, errors.Wrap(err, "cannot execute template"))
-		return rsp, nil
+	var data string
+	var docs []renderedDocument
+	if perEntry {
+		var stop bool
+		if data, docs, stop = executeTemplateEntries(rsp, tmpl, entries, reqMap, docErrs); stop {
+			return rsp, nil
+		}
+	} else {
+		buf := &bytes.Buffer{}
+		if err := tmpl.Execute(buf, reqMap); err != nil {
+			response.Fatal(rsp, errors.Wrap(err, "cannot execute template"))
+			return rsp, nil
+		}
+		data = buf.String()
+		docs = documentsOf(data, "")
 	}
+	numberDocuments(docs)
 
-	f.log.Debug("rendered manifests", "manifests", buf.String())
+	f.log.Debug("rendered manifests", "manifests", data)
 
 	// Parse the rendered manifests.
 	var objs []*unstructured.Unstructured
-	data := buf.String()
+	var objSources []documentSource
 	decoder := yaml.NewYAMLOrJSONDecoder(bytes.NewBufferString(data), 1024)
 
 	lines := strings.Split(data, "\n")
-	startLine := moveToNextDoc(lines, 1)
+	startLine := firstDocStart(lines)
 	docIndex := 0
 
-	for {
+	for chunk := 0; ; chunk++ {
 		u := &unstructured.Unstructured{}
 		if err := decoder.Decode(&u); err != nil {
 			if errors.Is(err, io.EOF) {
@@ -166,8 +203,20 @@ func (f *Function) RunFunction(_ context.Context, req *fnv1.RunFunctionRequest)
 				newErr = fmt.Errorf("error converting YAML to JSON: yaml: line %d (document %d, line %d) near: '%s': %s", yamlErr.AbsLine, docIndex+1, yamlErr.RelLine, ctx, yamlErr.Message)
 			}
 
-			response.Fatal(rsp, errors.Wrap(newErr, "cannot decode manifest"))
-			return rsp, nil
+			docErr := newDocumentError(docIndex, nil, errors.Wrap(newErr, "cannot decode manifest"))
+			if chunk < len(docs) {
+				docErr.Resources = resourceNamesOf(docs[chunk].Text)
+			}
+			if docErrs.Fail(rsp, docErr) {
+				return rsp, nil
+			}
+			// A JSON stream can't be decoded past an invalid document.
+			if yaml.IsJSONBuffer([]byte(data)) {
+				break
+			}
+			startLine = moveToNextDoc(lines, startLine)
+			docIndex++
+			continue
 		}
 
 		if u == nil {
@@ -179,12 +228,27 @@ func (f *Function) RunFunction(_ context.Context, req *fnv1.RunFunctionRequest)
 		// steps that assume string-only values, such as GetAnnotations.
 		if _, _, err := unstructured.NestedStringMap(u.Object, "metadata", "annotations"); err != nil {
 			m, _, _ := unstructured.NestedMap(u.Object, "metadata", "annotations")
-			response.Fatal(rsp, errors.Wrapf(err, "invalid annotations in resource '%s resource-name=%v'", u.GroupVersionKind(), m[annotationKeyCompositionResourceName]))
-			return rsp, nil
+			err = errors.Wrapf(err, "invalid annotations in resource '%s resource-name=%v'", u.GroupVersionKind(), m[annotationKeyCompositionResourceName])
+			docErr := &documentError{Document: docIndex + 1, Kind: u.GetKind(), Name: u.GetName(), Err: err}
+			if rn, ok := m[annotationKeyCompositionResourceName].(string); ok {
+				docErr.Resources = []string{rn}
+			}
+			if docErrs.Fail(rsp, docErr) {
+				return rsp, nil
+			}
+			startLine = moveToNextDoc(lines, startLine)
+			docIndex++
+			continue
 		}
 
 		objs = append(objs, u)
 
+		src := documentSource{Document: docIndex, Index: docIndex}
+		if chunk < len(docs) {
+			src.File, src.Index = docs[chunk].File, docs[chunk].Index
+		}
+		objSources = append(objSources, src)
+
 		startLine = moveToNextDoc(lines, startLine)
 		docIndex++
 	}
@@ -213,6 +277,18 @@ func (f *Function) RunFunction(_ context.Context, req *fnv1.RunFunctionRequest)
 	// Initialize the requirements.
 	requirements := &fnv1.Requirements{ExtraResources: make(map[string]*fnv1.ResourceSelector), Resources: make(map[string]*fnv1.ResourceSelector)}
 
+	// Load the schemas to validate composed resources against.
+	validator, err := newSchemaValidator(f.fsys, req, in.SchemaValidation, requirements)
+	if err != nil {
+		response.Fatal(rsp, errors.Wrap(err, "invalid function input"))
+		return rsp, nil
+	}
+	var invalid []string
+
+	// The composed resources rendered by this function, rather than by
+	// previous functions of the pipeline.
+	rendered := map[resource.Name]bool{}
+
 	// Override the TTL if specified in the observed composite.
 	if v, found := observedComposite.Resource.GetAnnotations()[annotationKeyTTL]; found {
 		t, err := time.ParseDuration(v)
@@ -224,19 +300,43 @@ func (f *Function) RunFunction(_ context.Context, req *fnv1.RunFunctionRequest)
 		meta.RemoveAnnotations(observedComposite.Resource, annotationKeyTTL)
 	}
 
+	// Derived names must not collide with the names set with the
+	// composition-resource-name annotation, regardless of the order of the
+	// resources.
+	if namer != nil {
+		for _, obj := range objs {
+			if name, ok := obj.GetAnnotations()[annotationKeyCompositionResourceName]; ok {
+				namer.Reserve(name)
+			}
+		}
+	}
+
 	// Convert the rendered manifests to a list of desired composed resources.
-	for _, obj := range objs {
+	for i, obj := range objs {
 		cd := resource.NewDesiredComposed()
 		cd.Resource.Unstructured = *obj.DeepCopy()
 
+		name, nameFound := obj.GetAnnotations()[annotationKeyCompositionResourceName]
+
+		// fail reports a problem with the current document.
+		fail := func(err error) bool {
+			docErr := newDocumentError(objSources[i].Document, obj, err)
+			if name != "" {
+				docErr.Resources = []string{name}
+			}
+			return docErrs.Fail(rsp, docErr)
+		}
+
 		// TODO(ezgidemirel): Refactor to reduce cyclomatic complexity.
 		// Check for ready state.
 		var ready *resource.Ready
 		if cd.Resource.GetAPIVersion() != metaAPIVersion {
 			if v, found := cd.Resource.GetAnnotations()[annotationKeyReady]; found {
 				if v != string(resource.ReadyTrue) && v != string(resource.ReadyUnspecified) && v != string(resource.ReadyFalse) {
-					response.Fatal(rsp, errors.Errorf("invalid function input: invalid %q annotation value %q: must be True, False, or Unspecified", annotationKeyReady, v))
-					return rsp, nil
+					if fail(errors.Errorf("invalid function input: invalid %q annotation value %q: must be True, False, or Unspecified", annotationKeyReady, v)) {
+						return rsp, nil
+					}
+					continue
 				}
 
 				r := resource.Ready(v)
@@ -250,16 +350,17 @@ func (f *Function) RunFunction(_ context.Context, req *fnv1.RunFunctionRequest)
 		// TODO(ezgidemirel): Refactor to reduce cyclomatic complexity.
 		// Handle if the composite resource appears in the rendered template.
 		// Unless resource name annotation is present, update only the status and ready state of the desired composite resource.
-		name, nameFound := obj.GetAnnotations()[annotationKeyCompositionResourceName]
-		if cd.Resource.GetAPIVersion() == observedComposite.Resource.GetAPIVersion() && cd.Resource.GetKind() == observedComposite.Resource.GetKind() && !nameFound {
+		if !operation && cd.Resource.GetAPIVersion() == observedComposite.Resource.GetAPIVersion() && cd.Resource.GetKind() == observedComposite.Resource.GetKind() && !nameFound {
 			dst := make(map[string]any)
 			dstExists := true
 			if err := desiredComposite.Resource.GetValueInto("status", &dst); err != nil {
 				if fieldpath.IsNotFound(err) {
 					dstExists = false
 				} else {
-					response.Fatal(rsp, errors.Wrap(err, "cannot get desired composite status"))
-					return rsp, nil
+					if fail(errors.Wrap(err, "cannot get desired composite status")) {
+						return rsp, nil
+					}
+					continue
 				}
 			}
 
@@ -269,21 +370,27 @@ func (f *Function) RunFunction(_ context.Context, req *fnv1.RunFunctionRequest)
 				if fieldpath.IsNotFound(err) {
 					srcExists = false
 				} else {
-					response.Fatal(rsp, errors.Wrap(err, "cannot get templated composite status"))
-					return rsp, nil
+					if fail(errors.Wrap(err, "cannot get templated composite status")) {
+						return rsp, nil
+					}
+					continue
 				}
 			}
 
 			// Only update status if there's either existing status or new status content.
 			if dstExists || srcExists {
 				if err := mergo.Merge(&dst, src, mergo.WithOverride); err != nil {
-					response.Fatal(rsp, errors.Wrap(err, "cannot merge desired composite status"))
-					return rsp, nil
+					if fail(errors.Wrap(err, "cannot merge desired composite status")) {
+						return rsp, nil
+					}
+					continue
 				}
 
 				if err := fieldpath.Pave(desiredComposite.Resource.Object).SetValue("status", dst); err != nil {
-					response.Fatal(rsp, errors.Wrap(err, "cannot set desired composite status"))
-					return rsp, nil
+					if fail(errors.Wrap(err, "cannot set desired composite status")) {
+						return rsp, nil
+					}
+					continue
 				}
 			}
 
@@ -297,6 +404,12 @@ func (f *Function) RunFunction(_ context.Context, req *fnv1.RunFunctionRequest)
 
 		// TODO(ezgidemirel): Refactor to reduce cyclomatic complexity.
 		if cd.Resource.GetAPIVersion() == metaAPIVersion {
+			if operation && (obj.GetKind() == "CompositeConnectionDetails" || obj.GetKind() == "ClaimConditions") {
+				if fail(errors.Errorf("invalid kind %q for apiVersion %q - not supported in operations, which have no composite resource", obj.GetKind(), metaAPIVersion)) {
+					return rsp, nil
+				}
+				continue
+			}
 			switch obj.GetKind() {
 			case "CompositeConnectionDetails":
 				// Set composite resource's connection details.
@@ -308,8 +421,16 @@ func (f *Function) RunFunction(_ context.Context, req *fnv1.RunFunctionRequest)
 			case "ClaimConditions":
 				var conditions []TargetedCondition
 				if err = cd.Resource.GetValueInto("conditions", &conditions); err != nil {
-					response.Fatal(rsp, errors.Wrap(err, "cannot get Conditions from input"))
-					return rsp, nil
+					if fail(errors.Wrap(err, "cannot get Conditions from input")) {
+						return rsp, nil
+					}
+					continue
+				}
+				if err := validateClaimConditions(conditions...); err != nil {
+					if fail(err) {
+						return rsp, nil
+					}
+					continue
 				}
 				err := UpdateClaimConditions(rsp, conditions...)
 				if err != nil {
@@ -319,20 +440,26 @@ func (f *Function) RunFunction(_ context.Context, req *fnv1.RunFunctionRequest)
 			case "Context":
 				contextData := make(map[string]any)
 				if err = cd.Resource.GetValueInto("data", &contextData); err != nil {
-					response.Fatal(rsp, errors.Wrap(err, "cannot get Contexts from input"))
-					return rsp, nil
+					if fail(errors.Wrap(err, "cannot get Contexts from input")) {
+						return rsp, nil
+					}
+					continue
 				}
 				mergedCtx, err := f.MergeContext(req, contextData)
 				if err != nil {
-					response.Fatal(rsp, errors.Wrapf(err, "cannot merge Context"))
-					return rsp, nil
+					if fail(errors.Wrapf(err, "cannot merge Context")) {
+						return rsp, nil
+					}
+					continue
 				}
 
 				for key, v := range mergedCtx {
 					vv, err := structpb.NewValue(v)
 					if err != nil {
-						response.Fatal(rsp, errors.Wrap(err, "cannot convert value to structpb.Value"))
-						return rsp, nil
+						if fail(errors.Wrap(err, "cannot convert value to structpb.Value")) {
+							return rsp, nil
+						}
+						continue
 					}
 					f.log.Debug("Updating Composition environment", "key", key, "data", v)
 					response.SetContextKey(rsp, key, vv)
@@ -341,22 +468,58 @@ func (f *Function) RunFunction(_ context.Context, req *fnv1.RunFunctionRequest)
 				// Set extra resources requirements.
 				ers := make(ExtraResourcesRequirements)
 				if err = cd.Resource.GetValueInto("requirements", &ers); err != nil {
-					response.Fatal(rsp, errors.Wrap(err, "cannot get extra resources requirements"))
-					return rsp, nil
+					if fail(errors.Wrap(err, "cannot get extra resources requirements")) {
+						return rsp, nil
+					}
+					continue
 				}
 				for k, v := range ers {
 					if _, found := requirements.GetExtraResources()[k]; found { //nolint:staticcheck // need to support Crossplane v1
-						response.Fatal(rsp, errors.Errorf("duplicate extra resource key %q", k))
-						return rsp, nil
+						if fail(errors.Errorf("duplicate extra resource key %q", k)) {
+							return rsp, nil
+						}
+						continue
 					}
 					requirements.Resources[k] = v.ToResourceSelector()
 					if v.Namespace == "" {
 						requirements.ExtraResources[k] = v.ToResourceSelector() //nolint:staticcheck // need to support Crossplane v1
 					}
 				}
+			case "OperationOutput":
+				if !operation {
+					if fail(errors.Errorf("invalid kind %q for apiVersion %q - only supported in operations", obj.GetKind(), metaAPIVersion)) {
+						return rsp, nil
+					}
+					continue
+				}
+				output := make(map[string]any)
+				if err = cd.Resource.GetValueInto("data", &output); err != nil {
+					if fail(errors.Wrap(err, "cannot get operation output from input")) {
+						return rsp, nil
+					}
+					continue
+				}
+				if err := MergeOperationOutput(rsp, output); err != nil {
+					if fail(err) {
+						return rsp, nil
+					}
+					continue
+				}
+				f.log.Debug("updating operation output", "output", output)
 			default:
-				response.Fatal(rsp, errors.Errorf("invalid kind %q for apiVersion %q - must be one of CompositeConnectionDetails, Context or ExtraResources", obj.GetKind(), metaAPIVersion))
-				return rsp, nil
+				h, ok := f.metaKinds[obj.GetKind()]
+				if !ok {
+					if fail(errors.Errorf("invalid kind %q for apiVersion %q - must be one of CompositeConnectionDetails, Context, ExtraResources or OperationOutput", obj.GetKind(), metaAPIVersion)) {
+						return rsp, nil
+					}
+					continue
+				}
+				if err := h(req, rsp, obj); err != nil {
+					if fail(errors.Wrapf(err, "cannot handle kind %q", obj.GetKind())) {
+						return rsp, nil
+					}
+					continue
+				}
 			}
 
 			continue
@@ -370,13 +533,92 @@ func (f *Function) RunFunction(_ context.Context, req *fnv1.RunFunctionRequest)
 		// Remove resource name annotation.
 		meta.RemoveAnnotations(cd.Resource, annotationKeyCompositionResourceName)
 
+		// Derive the name of resources without a resource name annotation.
+		if !nameFound && namer != nil {
+			if name, err = namer.Name(obj, objSources[i]); err != nil {
+				if fail(errors.Wrapf(err, "cannot derive composition resource name of %q template", obj.GetKind())) {
+					return rsp, nil
+				}
+				continue
+			}
+			nameFound = true
+		}
+
 		// Add resource to the desired composed resources map.
 		if !nameFound {
-			response.Fatal(rsp, errors.Errorf("%q template is missing required %q annotation", obj.GetKind(), annotationKeyCompositionResourceName))
-			return rsp, nil
+			if fail(errors.Errorf("%q template is missing required %q annotation", obj.GetKind(), annotationKeyCompositionResourceName)) {
+				return rsp, nil
+			}
+			continue
+		}
+
+		// Default the namespace of resources composed by namespaced XRs.
+		if err := setComposedNamespace(observedComposite.Resource, cd.Resource); err != nil {
+			if fail(errors.Wrapf(err, "invalid namespace of resource %q", name)) {
+				return rsp, nil
+			}
+			continue
+		}
+
+		// Validate the resource against the schema of its kind.
+		if validator != nil {
+			problems, err := validator.Validate(cd.Resource.UnstructuredContent(), cd.Resource.GroupVersionKind())
+			if err != nil {
+				if fail(errors.Wrapf(err, "cannot validate resource %q", name)) {
+					return rsp, nil
+				}
+				continue
+			}
+			if len(problems) > 0 && docErrs.PerDocument() {
+				if fail(errors.Errorf("invalid resource: %s", strings.Join(problems, "; "))) {
+					return rsp, nil
+				}
+				continue
+			}
+			for _, p := range problems {
+				invalid = append(invalid, fmt.Sprintf("document %d (%s %q, resource name %q): %s", objSources[i].Document+1, obj.GetKind(), obj.GetName(), name, p))
+			}
 		}
 
 		desiredComposed[resource.Name(name)] = cd
+		rendered[resource.Name(name)] = true
+	}
+
+	if docErrs.Report(rsp) {
+		return rsp, nil
+	}
+
+	// Keep the observed version of the composed resources of skipped
+	// documents, unless a previous function desired them. If the names of a
+	// skipped document are unknown, keep every observed composed resource that
+	// isn't desired, rather than deleting the resources of that document.
+	if skipped, unknown := docErrs.Skipped(); len(skipped) > 0 || unknown {
+		observedComposed, err := request.GetObservedComposedResources(req)
+		if err != nil {
+			response.Fatal(rsp, errors.Wrap(err, "cannot get observed composed resources"))
+			return rsp, nil
+		}
+		for name, oc := range observedComposed {
+			if _, desired := desiredComposed[name]; desired {
+				continue
+			}
+			if unknown || slices.Contains(skipped, string(name)) {
+				desiredComposed[name] = keepObserved(oc)
+			}
+		}
+	}
+
+	if len(invalid) > 0 {
+		response.Fatal(rsp, errors.Errorf("invalid composed resources: %s", strings.Join(invalid, "; ")))
+		return rsp, nil
+	}
+
+	// Report the changes of the composed resources for debugging.
+	if in.Diff != nil {
+		if err := reportDesiredDiff(rsp, f.log, req, desiredComposed, rendered, in.Diff); err != nil {
+			response.Fatal(rsp, errors.Wrap(err, "cannot diff desired composed resources"))
+			return rsp, nil
+		}
 	}
 
 	f.log.Debug("desired composite resource", "desiredComposite:", desiredComposite)
@@ -387,12 +629,14 @@ func (f *Function) RunFunction(_ context.Context, req *fnv1.RunFunctionRequest)
 		return rsp, nil
 	}
 
-	if err := response.SetDesiredCompositeResource(rsp, desiredComposite); err != nil {
-		response.Fatal(rsp, errors.Wrap(err, "cannot set desired composite resource"))
-		return rsp, nil
+	if !operation {
+		if err := response.SetDesiredCompositeResource(rsp, desiredComposite); err != nil {
+			response.Fatal(rsp, errors.Wrap(err, "cannot set desired composite resource"))
+			return rsp, nil
+		}
 	}
 
-	if len(requirements.GetExtraResources()) > 0 || len(requirements.GetResources()) > 0 { //nolint:staticcheck // need to support Crossplane v1
+	if len(requirements.GetExtraResources()) > 0 || len(requirements.GetResources()) > 0 || len(requirements.GetSchemas()) > 0 { //nolint:staticcheck // need to support Crossplane v1
 		rsp.Requirements = requirements
 	}
 
@@ -450,9 +694,23 @@ func safeApplyTemplateOptions(templ *template.Template, options []string) (err e
 	return nil
 }
 
+// firstDocStart returns the line of the separator before the first document,
+// or 0 if the first document is not preceded by a separator.
+func firstDocStart(lines []string) int {
+	for i, l := range lines {
+		switch t := strings.TrimSpace(l); {
+		case t == "---":
+			return i + 1
+		case t != "" && !strings.HasPrefix(t, "#"):
+			return 0
+		}
+	}
+	return 0
+}
+
 func moveToNextDoc(lines []string, startLine int) int {
-	for i := startLine; i <= len(lines); i++ {
-		if strings.TrimSpace(lines[i-1]) == "---" && i > startLine {
+	for i := startLine + 1; i <= len(lines); i++ {
+		if strings.TrimSpace(lines[i-1]) == "---" {
 			return i
 		}
 	}
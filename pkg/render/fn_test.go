@@ -1,4 +1,4 @@
-package main
+package render
 
 import (
 	"context"
@@ -67,11 +67,90 @@ metadata:
 {"apiVersion":"meta.gotemplating.fn.crossplane.io/v1alpha1","kind":"ExtraResources","requirements":{"all-cool-resources":{"apiVersion":"example.org/v1","kind":"CoolExtraResource","matchLabels":{}}}}`
 	extraResourcesDuplicatedKey = `{"apiVersion":"meta.gotemplating.fn.crossplane.io/v1alpha1","kind":"ExtraResources","requirements":{"cool-extra-resource":{"apiVersion":"example.org/v1","kind":"CoolExtraResource","matchName":"cool-extra-resource"}}}
 {"apiVersion":"meta.gotemplating.fn.crossplane.io/v1alpha1","kind":"ExtraResources","requirements":{"cool-extra-resource":{"apiVersion":"example.org/v1","kind":"CoolExtraResource","matchName":"another-cool-extra-resource"}}}`
+	operationTmpl = `{"apiVersion":"example.org/v1","kind":"CD","metadata":{"annotations":{"gotemplating.fn.crossplane.io/composition-resource-name":"cool-cd"},"name":"cool-cd","labels":{"operation":{{ isOperation . | quote }},"watched":{{ (getWatchedResource .).metadata.name | quote }}}}}
+{"apiVersion":"meta.gotemplating.fn.crossplane.io/v1alpha1","kind":"OperationOutput","data":{"watched":{{ (getWatchedResource .).metadata.name | quote }}}}
+{"apiVersion":"meta.gotemplating.fn.crossplane.io/v1alpha1","kind":"OperationOutput","data":{"count":1}}`
+	metaResourceOperationOutput = `{"apiVersion":"meta.gotemplating.fn.crossplane.io/v1alpha1","kind":"OperationOutput","data":{"key":"value"}}`
+	xrNamespaced                = `{"apiVersion":"example.org/v1","kind":"XR","metadata":{"name":"cool-xr","namespace":"cool-ns"},"spec":{"count":2}}`
+	cdsNamespaced               = `{"apiVersion":"example.org/v1","kind":"CD","metadata":{"annotations":{"gotemplating.fn.crossplane.io/composition-resource-name":"cool-cd"},"name":"cool-cd"}}
+{"apiVersion":"example.org/v1","kind":"ClusterCD","metadata":{"annotations":{"gotemplating.fn.crossplane.io/composition-resource-name":"cool-cluster-cd","gotemplating.fn.crossplane.io/cluster-scoped":"true"},"name":"cool-cluster-cd"}}`
+	cdOtherNamespace        = `{"apiVersion":"example.org/v1","kind":"CD","metadata":{"annotations":{"gotemplating.fn.crossplane.io/composition-resource-name":"cool-cd"},"name":"cool-cd","namespace":"other-ns"}}`
 	extraResourceNamespaced = `{"apiVersion":"meta.gotemplating.fn.crossplane.io/v1alpha1","kind":"ExtraResources","requirements":{"cool-extra-resource":{"apiVersion":"v1","kind":"ConfigMap","matchName":"cool-extra-resource","namespace":"default"}}}`
 
+	cdsUnnamed = `{"apiVersion":"example.org/v1","kind":"CD","metadata":{"name":"cool-cd"}}
+---
+{"apiVersion":"example.org/v1","kind":"CD","metadata":{"annotations":{"gotemplating.fn.crossplane.io/composition-resource-name":"named-cd"},"name":"another-cd"}}`
+
+	bucketsInvalid = `{"apiVersion":"example.org/v1","kind":"Bucket","metadata":{"annotations":{"gotemplating.fn.crossplane.io/composition-resource-name":"logs"},"name":"cool-logs"},"spec":{"forProvder":{"region":"eu-west-1"}}}
+---
+{"apiVersion":"example.org/v1","kind":"Bucket","metadata":{"annotations":{"gotemplating.fn.crossplane.io/composition-resource-name":"data"},"name":"cool-data"},"spec":{"forProvider":{"region":"eu-west-1","versioning":"yes"}}}
+---
+{"apiVersion":"example.org/v1","kind":"CD","metadata":{"annotations":{"gotemplating.fn.crossplane.io/composition-resource-name":"cool-cd"},"name":"cool-cd"},"spec":{"anything":true}}`
+	bucketValid = `{"apiVersion":"example.org/v1","kind":"Bucket","metadata":{"annotations":{"gotemplating.fn.crossplane.io/composition-resource-name":"logs"},"name":"cool-logs"},"spec":{"forProvider":{"region":"eu-west-1","tags":{"team":"platform"}}}}`
+
+	docsWithErrors = `apiVersion: example.org/v1
+kind: CD
+metadata:
+  name: no-name
+---
+apiVersion: example.org/v1
+kind: CD
+metadata:
+  name: [broken
+---
+apiVersion: meta.gotemplating.fn.crossplane.io/v1alpha1
+kind: InvalidMeta
+---
+apiVersion: example.org/v1
+kind: CD
+metadata:
+  annotations:
+    gotemplating.fn.crossplane.io/composition-resource-name: cool-cd
+  name: cool-cd
+`
+
+	docsWithInvalidBucket = `apiVersion: example.org/v1
+kind: Bucket
+metadata:
+  annotations:
+    gotemplating.fn.crossplane.io/composition-resource-name: logs
+  name: [broken
+---
+apiVersion: example.org/v1
+kind: Bucket
+metadata:
+  name: no-name
+---
+apiVersion: example.org/v1
+kind: CD
+metadata:
+  annotations:
+    gotemplating.fn.crossplane.io/composition-resource-name: cool-cd
+  name: cool-cd
+`
+
+	bucketFailTmpl = `apiVersion: example.org/v1
+kind: Bucket
+metadata:
+  annotations:
+    gotemplating.fn.crossplane.io/composition-resource-name: logs
+  name: {{ fail "no region" }}
+`
+	bucketFailNameAnnotationTmpl = `apiVersion: example.org/v1
+kind: Bucket
+metadata:
+  annotations:
+    {{ setResourceNameAnnotation "a" }}
+  name: {{ fail "boom" }}
+`
+
 	key       = "userkey/go-template"
 	path      = "testdata/templates"
 	wrongPath = "testdata/wrong"
+	unnamed   = "testdata/unnamed"
+	trimmed   = "testdata/trimmed"
+	skipped   = "testdata/skipped"
+	crds      = "testdata/crds"
 
 	//go:embed testdata
 	testdataFS embed.FS
@@ -833,7 +912,7 @@ func TestRunFunction(t *testing.T) {
 					Results: []*fnv1.Result{
 						{
 							Severity: fnv1.Severity_SEVERITY_FATAL,
-							Message:  "invalid kind \"InvalidMeta\" for apiVersion \"" + metaAPIVersion + "\" - must be one of CompositeConnectionDetails, Context or ExtraResources",
+							Message:  "invalid kind \"InvalidMeta\" for apiVersion \"" + metaAPIVersion + "\" - must be one of CompositeConnectionDetails, Context, ExtraResources or OperationOutput",
 							Target:   fnv1.Target_TARGET_COMPOSITE.Enum(),
 						},
 					},
@@ -1889,6 +1968,939 @@ func TestRunFunction(t *testing.T) {
 				},
 			},
 		},
+		"Operation": {
+			reason: "The Function should return desired resources and output, but no desired composite, for operations.",
+			args: args{
+				req: &fnv1.RunFunctionRequest{
+					Input: resource.MustStructObject(
+						&v1beta1.GoTemplate{
+							Source: v1beta1.InlineSource,
+							Inline: &v1beta1.TemplateSourceInline{Template: operationTmpl},
+						}),
+					RequiredResources: map[string]*fnv1.Resources{
+						"ops.crossplane.io/watched-resource": {
+							Items: []*fnv1.Resource{
+								{
+									Resource: resource.MustStructJSON(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"cool-cm","namespace":"default"}}`),
+								},
+							},
+						},
+					},
+				},
+			},
+			want: want{
+				rsp: &fnv1.RunFunctionResponse{
+					Meta: &fnv1.ResponseMeta{Ttl: durationpb.New(response.DefaultTTL)},
+					Desired: &fnv1.State{
+						Resources: map[string]*fnv1.Resource{
+							"cool-cd": {
+								Resource: resource.MustStructJSON(`{"apiVersion":"example.org/v1","kind":"CD","metadata":{"annotations":{},"name":"cool-cd","labels":{"operation":"true","watched":"cool-cm"}}}`),
+							},
+						},
+					},
+					Output:  resource.MustStructJSON(`{"watched":"cool-cm","count":1}`),
+					Context: resource.MustStructJSON(`{"apiextensions.crossplane.io/extra-resources":{"ops.crossplane.io/watched-resource":{"items":[{"resource":{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"cool-cm","namespace":"default"}}}]}}}`),
+				},
+			},
+		},
+		"OperationMode": {
+			reason: "The Function should render for an operation if the input sets the Operation mode, even if the request has neither capabilities nor a watched resource.",
+			args: args{
+				req: &fnv1.RunFunctionRequest{
+					Input: resource.MustStructObject(
+						&v1beta1.GoTemplate{
+							Source: v1beta1.InlineSource,
+							Inline: &v1beta1.TemplateSourceInline{Template: `{{ if isOperation . }}` + metaResourceOperationOutput + `{{ end }}`},
+							Mode:   v1beta1.OperationMode,
+						}),
+				},
+			},
+			want: want{
+				rsp: &fnv1.RunFunctionResponse{
+					Meta:    &fnv1.ResponseMeta{Ttl: durationpb.New(response.DefaultTTL)},
+					Desired: &fnv1.State{},
+					Output:  resource.MustStructJSON(`{"key":"value"}`),
+				},
+			},
+		},
+		"OperationAutoModeWithoutCapabilities": {
+			reason: "The Function should render for a composition if the request has neither capabilities nor a watched resource, and the input doesn't set the Operation mode.",
+			args: args{
+				req: &fnv1.RunFunctionRequest{
+					Input: resource.MustStructObject(
+						&v1beta1.GoTemplate{
+							Source: v1beta1.InlineSource,
+							Inline: &v1beta1.TemplateSourceInline{Template: metaResourceOperationOutput},
+						}),
+				},
+			},
+			want: want{
+				rsp: &fnv1.RunFunctionResponse{
+					Meta: &fnv1.ResponseMeta{Ttl: durationpb.New(response.DefaultTTL)},
+					Results: []*fnv1.Result{
+						{
+							Severity: fnv1.Severity_SEVERITY_FATAL,
+							Message:  "invalid kind \"OperationOutput\" for apiVersion \"" + metaAPIVersion + "\" - only supported in operations",
+							Target:   fnv1.Target_TARGET_COMPOSITE.Enum(),
+						},
+					},
+				},
+			},
+		},
+		"OperationOutputInComposition": {
+			reason: "The Function should return a fatal result if a composition writes operation output.",
+			args: args{
+				req: &fnv1.RunFunctionRequest{
+					Input: resource.MustStructObject(
+						&v1beta1.GoTemplate{
+							Source: v1beta1.InlineSource,
+							Inline: &v1beta1.TemplateSourceInline{Template: metaResourceOperationOutput},
+						}),
+					Observed: &fnv1.State{
+						Composite: &fnv1.Resource{
+							Resource: resource.MustStructJSON(xr),
+						},
+					},
+				},
+			},
+			want: want{
+				rsp: &fnv1.RunFunctionResponse{
+					Meta: &fnv1.ResponseMeta{Ttl: durationpb.New(response.DefaultTTL)},
+					Results: []*fnv1.Result{
+						{
+							Severity: fnv1.Severity_SEVERITY_FATAL,
+							Message:  "invalid kind \"OperationOutput\" for apiVersion \"" + metaAPIVersion + "\" - only supported in operations",
+							Target:   fnv1.Target_TARGET_COMPOSITE.Enum(),
+						},
+					},
+				},
+			},
+		},
+		"CompositeConnectionDetailsInOperation": {
+			reason: "The Function should return a fatal result if an operation sets composite connection details.",
+			args: args{
+				req: &fnv1.RunFunctionRequest{
+					Input: resource.MustStructObject(
+						&v1beta1.GoTemplate{
+							Source: v1beta1.InlineSource,
+							Inline: &v1beta1.TemplateSourceInline{Template: metaResourceConDet},
+						}),
+					Meta: &fnv1.RequestMeta{
+						Capabilities: []fnv1.Capability{fnv1.Capability_CAPABILITY_CAPABILITIES},
+					},
+				},
+			},
+			want: want{
+				rsp: &fnv1.RunFunctionResponse{
+					Meta: &fnv1.ResponseMeta{Ttl: durationpb.New(response.DefaultTTL)},
+					Results: []*fnv1.Result{
+						{
+							Severity: fnv1.Severity_SEVERITY_FATAL,
+							Message:  "invalid kind \"CompositeConnectionDetails\" for apiVersion \"" + metaAPIVersion + "\" - not supported in operations, which have no composite resource",
+							Target:   fnv1.Target_TARGET_COMPOSITE.Enum(),
+						},
+					},
+				},
+			},
+		},
+		"NamespacedComposite": {
+			reason: "The Function should default the namespace of composed resources to the namespace of the composite resource, unless they are cluster scoped.",
+			args: args{
+				req: &fnv1.RunFunctionRequest{
+					Input: resource.MustStructObject(
+						&v1beta1.GoTemplate{
+							Source: v1beta1.InlineSource,
+							Inline: &v1beta1.TemplateSourceInline{Template: cdsNamespaced},
+						}),
+					Observed: &fnv1.State{
+						Composite: &fnv1.Resource{
+							Resource: resource.MustStructJSON(xrNamespaced),
+						},
+					},
+					Desired: &fnv1.State{
+						Composite: &fnv1.Resource{
+							Resource: resource.MustStructJSON(xrNamespaced),
+						},
+					},
+				},
+			},
+			want: want{
+				rsp: &fnv1.RunFunctionResponse{
+					Meta: &fnv1.ResponseMeta{Ttl: durationpb.New(response.DefaultTTL)},
+					Desired: &fnv1.State{
+						Composite: &fnv1.Resource{
+							Resource: resource.MustStructJSON(xrNamespaced),
+						},
+						Resources: map[string]*fnv1.Resource{
+							"cool-cd": {
+								Resource: resource.MustStructJSON(`{"apiVersion":"example.org/v1","kind":"CD","metadata":{"annotations":{},"name":"cool-cd","namespace":"cool-ns"}}`),
+							},
+							"cool-cluster-cd": {
+								Resource: resource.MustStructJSON(`{"apiVersion":"example.org/v1","kind":"ClusterCD","metadata":{"annotations":{},"name":"cool-cluster-cd"}}`),
+							},
+						},
+					},
+				},
+			},
+		},
+		"CrossNamespaceComposedResource": {
+			reason: "The Function should return a fatal result if a composed resource is not in the namespace of the composite resource.",
+			args: args{
+				req: &fnv1.RunFunctionRequest{
+					Input: resource.MustStructObject(
+						&v1beta1.GoTemplate{
+							Source: v1beta1.InlineSource,
+							Inline: &v1beta1.TemplateSourceInline{Template: cdOtherNamespace},
+						}),
+					Observed: &fnv1.State{
+						Composite: &fnv1.Resource{
+							Resource: resource.MustStructJSON(xrNamespaced),
+						},
+					},
+				},
+			},
+			want: want{
+				rsp: &fnv1.RunFunctionResponse{
+					Meta: &fnv1.ResponseMeta{Ttl: durationpb.New(response.DefaultTTL)},
+					Results: []*fnv1.Result{
+						{
+							Severity: fnv1.Severity_SEVERITY_FATAL,
+							Message:  `invalid namespace of resource "cool-cd": "CD" "cool-cd" must be in the namespace "cool-ns" of the composite resource, not "other-ns" - set the "gotemplating.fn.crossplane.io/cluster-scoped" annotation to "true" if it is cluster scoped`,
+							Target:   fnv1.Target_TARGET_COMPOSITE.Enum(),
+						},
+					},
+				},
+			},
+		},
+		"ResourceNamingKindAndName": {
+			reason: "The Function should derive the name of resources without a resource name annotation from their kind and name.",
+			args: args{
+				req: &fnv1.RunFunctionRequest{
+					Input: resource.MustStructObject(
+						&v1beta1.GoTemplate{
+							Source:         v1beta1.InlineSource,
+							Inline:         &v1beta1.TemplateSourceInline{Template: cdsUnnamed},
+							ResourceNaming: &v1beta1.ResourceNaming{Policy: v1beta1.KindAndNamePolicy},
+						}),
+					Observed: &fnv1.State{
+						Composite: &fnv1.Resource{
+							Resource: resource.MustStructJSON(xr),
+						},
+					},
+				},
+			},
+			want: want{
+				rsp: &fnv1.RunFunctionResponse{
+					Meta: &fnv1.ResponseMeta{Ttl: durationpb.New(response.DefaultTTL)},
+					Desired: &fnv1.State{
+						Composite: &fnv1.Resource{
+							Resource: resource.MustStructJSON(`{}`),
+						},
+						Resources: map[string]*fnv1.Resource{
+							"cd-cool-cd": {
+								Resource: resource.MustStructJSON(`{"apiVersion":"example.org/v1","kind":"CD","metadata":{"name":"cool-cd"}}`),
+							},
+							"named-cd": {
+								Resource: resource.MustStructJSON(`{"apiVersion":"example.org/v1","kind":"CD","metadata":{"annotations":{},"name":"another-cd"}}`),
+							},
+						},
+					},
+				},
+			},
+		},
+		"ResourceNamingTemplate": {
+			reason: "The Function should derive the name of resources without a resource name annotation from a template expression.",
+			args: args{
+				req: &fnv1.RunFunctionRequest{
+					Input: resource.MustStructObject(
+						&v1beta1.GoTemplate{
+							Source: v1beta1.InlineSource,
+							Inline: &v1beta1.TemplateSourceInline{Template: cdsUnnamed},
+							ResourceNaming: &v1beta1.ResourceNaming{
+								Policy:   v1beta1.TemplatePolicy,
+								Template: `{{ .resource.metadata.name | trimPrefix "cool-" }}`,
+							},
+						}),
+					Observed: &fnv1.State{
+						Composite: &fnv1.Resource{
+							Resource: resource.MustStructJSON(xr),
+						},
+					},
+				},
+			},
+			want: want{
+				rsp: &fnv1.RunFunctionResponse{
+					Meta: &fnv1.ResponseMeta{Ttl: durationpb.New(response.DefaultTTL)},
+					Desired: &fnv1.State{
+						Composite: &fnv1.Resource{
+							Resource: resource.MustStructJSON(`{}`),
+						},
+						Resources: map[string]*fnv1.Resource{
+							"cd": {
+								Resource: resource.MustStructJSON(`{"apiVersion":"example.org/v1","kind":"CD","metadata":{"name":"cool-cd"}}`),
+							},
+							"named-cd": {
+								Resource: resource.MustStructJSON(`{"apiVersion":"example.org/v1","kind":"CD","metadata":{"annotations":{},"name":"another-cd"}}`),
+							},
+						},
+					},
+				},
+			},
+		},
+		"ResourceNamingFileAndIndex": {
+			reason: "The Function should derive the name of resources without a resource name annotation from their template file and index.",
+			args: args{
+				req: &fnv1.RunFunctionRequest{
+					Input: resource.MustStructObject(
+						&v1beta1.GoTemplate{
+							Source:         v1beta1.FileSystemSource,
+							FileSystem:     &v1beta1.TemplateSourceFileSystem{DirPath: unnamed},
+							ResourceNaming: &v1beta1.ResourceNaming{Policy: v1beta1.FileAndIndexPolicy},
+						}),
+					Observed: &fnv1.State{
+						Composite: &fnv1.Resource{
+							Resource: resource.MustStructJSON(xr),
+						},
+					},
+				},
+			},
+			want: want{
+				rsp: &fnv1.RunFunctionResponse{
+					Meta: &fnv1.ResponseMeta{Ttl: durationpb.New(response.DefaultTTL)},
+					Desired: &fnv1.State{
+						Composite: &fnv1.Resource{
+							Resource: resource.MustStructJSON(`{}`),
+						},
+						Resources: map[string]*fnv1.Resource{
+							"buckets-0": {
+								Resource: resource.MustStructJSON(`{"apiVersion":"example.org/v1","kind":"Bucket","metadata":{"name":"cool-xr-logs"}}`),
+							},
+							"buckets-1": {
+								Resource: resource.MustStructJSON(`{"apiVersion":"example.org/v1","kind":"Bucket","metadata":{"name":"cool-xr-data"}}`),
+							},
+						},
+					},
+				},
+			},
+		},
+		"FileSystemTrimmedTemplate": {
+			reason: "The Function should render template files that start with a trim marker as they are.",
+			args: args{
+				req: &fnv1.RunFunctionRequest{
+					Input: resource.MustStructObject(
+						&v1beta1.GoTemplate{
+							Source:     v1beta1.FileSystemSource,
+							FileSystem: &v1beta1.TemplateSourceFileSystem{DirPath: trimmed},
+						}),
+					Observed: &fnv1.State{
+						Composite: &fnv1.Resource{
+							Resource: resource.MustStructJSON(xr),
+						},
+					},
+				},
+			},
+			want: want{
+				rsp: &fnv1.RunFunctionResponse{
+					Meta: &fnv1.ResponseMeta{Ttl: durationpb.New(response.DefaultTTL)},
+					Desired: &fnv1.State{
+						Composite: &fnv1.Resource{
+							Resource: resource.MustStructJSON(`{}`),
+						},
+						Resources: map[string]*fnv1.Resource{
+							"cm": {
+								Resource: resource.MustStructJSON(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"annotations":{},"name":"cool-cm"}}`),
+							},
+						},
+					},
+				},
+			},
+		},
+		"ResourceNamingFileAndIndexSkippedDocument": {
+			reason: "The Function should number documents before decoding them, so that a skipped document doesn't change the index of the following ones.",
+			args: args{
+				req: &fnv1.RunFunctionRequest{
+					Input: resource.MustStructObject(
+						&v1beta1.GoTemplate{
+							Source:         v1beta1.FileSystemSource,
+							FileSystem:     &v1beta1.TemplateSourceFileSystem{DirPath: skipped},
+							ResourceNaming: &v1beta1.ResourceNaming{Policy: v1beta1.FileAndIndexPolicy},
+							FailurePolicy:  v1beta1.SkipDocumentFailurePolicy,
+						}),
+					Observed: &fnv1.State{
+						Composite: &fnv1.Resource{
+							Resource: resource.MustStructJSON(xr),
+						},
+					},
+				},
+			},
+			want: want{
+				rsp: &fnv1.RunFunctionResponse{
+					Meta: &fnv1.ResponseMeta{Ttl: durationpb.New(response.DefaultTTL)},
+					Results: []*fnv1.Result{
+						{
+							Severity: fnv1.Severity_SEVERITY_WARNING,
+							Message:  "skipping invalid document: document 1: cannot decode manifest: error converting YAML to JSON: yaml: line 4 (document 1, line 4) near: 'name: [broken': did not find expected ',' or ']'",
+							Target:   fnv1.Target_TARGET_COMPOSITE.Enum(),
+						},
+					},
+					Desired: &fnv1.State{
+						Composite: &fnv1.Resource{
+							Resource: resource.MustStructJSON(`{}`),
+						},
+						Resources: map[string]*fnv1.Resource{
+							"buckets-1": {
+								Resource: resource.MustStructJSON(`{"apiVersion":"example.org/v1","kind":"Bucket","metadata":{"name":"cool-xr-data"}}`),
+							},
+						},
+					},
+				},
+			},
+		},
+		"ResourceNamingAnnotationCollision": {
+			reason: "The Function should return a fatal result if a derived name is set with the resource name annotation of another resource.",
+			args: args{
+				req: &fnv1.RunFunctionRequest{
+					Input: resource.MustStructObject(
+						&v1beta1.GoTemplate{
+							Source: v1beta1.InlineSource,
+							Inline: &v1beta1.TemplateSourceInline{Template: `{"apiVersion":"example.org/v1","kind":"Bucket","metadata":{"name":"logs"}}
+{"apiVersion":"example.org/v1","kind":"Bucket","metadata":{"name":"data","annotations":{"gotemplating.fn.crossplane.io/composition-resource-name":"template-0"}}}`},
+							ResourceNaming: &v1beta1.ResourceNaming{Policy: v1beta1.FileAndIndexPolicy},
+						}),
+					Observed: &fnv1.State{
+						Composite: &fnv1.Resource{
+							Resource: resource.MustStructJSON(xr),
+						},
+					},
+				},
+			},
+			want: want{
+				rsp: &fnv1.RunFunctionResponse{
+					Meta: &fnv1.ResponseMeta{Ttl: durationpb.New(response.DefaultTTL)},
+					Results: []*fnv1.Result{
+						{
+							Severity: fnv1.Severity_SEVERITY_FATAL,
+							Message:  `cannot derive composition resource name of "Bucket" template: derived composition resource name "template-0" is already set by the "gotemplating.fn.crossplane.io/composition-resource-name" annotation of another resource`,
+							Target:   fnv1.Target_TARGET_COMPOSITE.Enum(),
+						},
+					},
+				},
+			},
+		},
+		"ResourceNamingInvalidPolicy": {
+			reason: "The Function should return a fatal result if the resource naming policy is invalid.",
+			args: args{
+				req: &fnv1.RunFunctionRequest{
+					Input: resource.MustStructObject(
+						&v1beta1.GoTemplate{
+							Source:         v1beta1.InlineSource,
+							Inline:         &v1beta1.TemplateSourceInline{Template: cdsUnnamed},
+							ResourceNaming: &v1beta1.ResourceNaming{Policy: "Random"},
+						}),
+				},
+			},
+			want: want{
+				rsp: &fnv1.RunFunctionResponse{
+					Meta: &fnv1.ResponseMeta{Ttl: durationpb.New(response.DefaultTTL)},
+					Results: []*fnv1.Result{
+						{
+							Severity: fnv1.Severity_SEVERITY_FATAL,
+							Message:  `invalid function input: invalid resourceNaming.policy "Random": must be one of KindAndName, Template or FileAndIndex`,
+							Target:   fnv1.Target_TARGET_COMPOSITE.Enum(),
+						},
+					},
+				},
+			},
+		},
+		"SchemaValidationInvalid": {
+			reason: "The Function should return a fatal result listing all problems of resources that don't match the schemas of their CRDs.",
+			args: args{
+				req: &fnv1.RunFunctionRequest{
+					Input: resource.MustStructObject(
+						&v1beta1.GoTemplate{
+							Source:           v1beta1.InlineSource,
+							Inline:           &v1beta1.TemplateSourceInline{Template: bucketsInvalid},
+							SchemaValidation: &v1beta1.SchemaValidation{DirPath: crds},
+						}),
+					Observed: &fnv1.State{
+						Composite: &fnv1.Resource{
+							Resource: resource.MustStructJSON(xr),
+						},
+					},
+				},
+			},
+			want: want{
+				rsp: &fnv1.RunFunctionResponse{
+					Meta: &fnv1.ResponseMeta{Ttl: durationpb.New(response.DefaultTTL)},
+					Results: []*fnv1.Result{
+						{
+							Severity: fnv1.Severity_SEVERITY_FATAL,
+							Message:  `invalid composed resources: document 1 (Bucket "cool-logs", resource name "logs"): spec.forProvder: unknown field; document 1 (Bucket "cool-logs", resource name "logs"): spec.forProvider: required field is missing; document 2 (Bucket "cool-data", resource name "data"): spec.forProvider.versioning: expected boolean, got string`,
+							Target:   fnv1.Target_TARGET_COMPOSITE.Enum(),
+						},
+					},
+				},
+			},
+		},
+		"SchemaValidationInvalidPerDocumentFatal": {
+			reason: "The Function should return a fatal result with the problems of all invalid resources, and no desired resources, if documents are executed separately with the Fatal failure policy.",
+			args: args{
+				req: &fnv1.RunFunctionRequest{
+					Input: resource.MustStructObject(
+						&v1beta1.GoTemplate{
+							Source:           v1beta1.InlineSource,
+							Inline:           &v1beta1.TemplateSourceInline{Template: bucketsInvalid},
+							SchemaValidation: &v1beta1.SchemaValidation{DirPath: crds},
+							ExecutionMode:    v1beta1.PerDocumentExecutionMode,
+							ErrorMode:        v1beta1.AggregateErrorMode,
+							FailurePolicy:    v1beta1.FatalFailurePolicy,
+						}),
+					Observed: &fnv1.State{
+						Composite: &fnv1.Resource{
+							Resource: resource.MustStructJSON(xr),
+						},
+					},
+				},
+			},
+			want: want{
+				rsp: &fnv1.RunFunctionResponse{
+					Meta: &fnv1.ResponseMeta{Ttl: durationpb.New(response.DefaultTTL)},
+					Results: []*fnv1.Result{
+						{
+							Severity: fnv1.Severity_SEVERITY_WARNING,
+							Message:  "document 1 (Bucket \"logs\"): invalid resource: spec.forProvder: unknown field; spec.forProvider: required field is missing",
+							Target:   fnv1.Target_TARGET_COMPOSITE.Enum(),
+						},
+						{
+							Severity: fnv1.Severity_SEVERITY_WARNING,
+							Message:  "document 2 (Bucket \"data\"): invalid resource: spec.forProvider.versioning: expected boolean, got string",
+							Target:   fnv1.Target_TARGET_COMPOSITE.Enum(),
+						},
+						{
+							Severity: fnv1.Severity_SEVERITY_FATAL,
+							Message:  "cannot compose resources, found 2 problems in rendered documents: document 1 (Bucket \"logs\"): invalid resource: spec.forProvder: unknown field; spec.forProvider: required field is missing; document 2 (Bucket \"data\"): invalid resource: spec.forProvider.versioning: expected boolean, got string",
+							Target:   fnv1.Target_TARGET_COMPOSITE.Enum(),
+						},
+					},
+				},
+			},
+		},
+		"SchemaValidationRequireSchemas": {
+			reason: "The Function should require the schemas of composed resources that are not in the CRD directory.",
+			args: args{
+				req: &fnv1.RunFunctionRequest{
+					Input: resource.MustStructObject(
+						&v1beta1.GoTemplate{
+							Source:           v1beta1.InlineSource,
+							Inline:           &v1beta1.TemplateSourceInline{Template: bucketValid},
+							SchemaValidation: &v1beta1.SchemaValidation{RequireSchemas: true},
+						}),
+					Observed: &fnv1.State{
+						Composite: &fnv1.Resource{
+							Resource: resource.MustStructJSON(xr),
+						},
+					},
+				},
+			},
+			want: want{
+				rsp: &fnv1.RunFunctionResponse{
+					Meta: &fnv1.ResponseMeta{Ttl: durationpb.New(response.DefaultTTL)},
+					Desired: &fnv1.State{
+						Composite: &fnv1.Resource{
+							Resource: resource.MustStructJSON(`{}`),
+						},
+						Resources: map[string]*fnv1.Resource{
+							"logs": {
+								Resource: resource.MustStructJSON(`{"apiVersion":"example.org/v1","kind":"Bucket","metadata":{"annotations":{},"name":"cool-logs"},"spec":{"forProvider":{"region":"eu-west-1","tags":{"team":"platform"}}}}`),
+							},
+						},
+					},
+					Requirements: &fnv1.Requirements{
+						Schemas: map[string]*fnv1.SchemaSelector{
+							"schema/example.org/v1/Bucket": {ApiVersion: "example.org/v1", Kind: "Bucket"},
+						},
+					},
+				},
+			},
+		},
+		"SchemaValidationRequiredSchema": {
+			reason: "The Function should validate composed resources against the schemas resolved by Crossplane.",
+			args: args{
+				req: &fnv1.RunFunctionRequest{
+					Input: resource.MustStructObject(
+						&v1beta1.GoTemplate{
+							Source:           v1beta1.InlineSource,
+							Inline:           &v1beta1.TemplateSourceInline{Template: bucketValid},
+							SchemaValidation: &v1beta1.SchemaValidation{RequireSchemas: true},
+						}),
+					Observed: &fnv1.State{
+						Composite: &fnv1.Resource{
+							Resource: resource.MustStructJSON(xr),
+						},
+					},
+					RequiredSchemas: map[string]*fnv1.Schema{
+						"schema/example.org/v1/Bucket": {
+							OpenapiV3: resource.MustStructJSON(`{"type":"object","properties":{"spec":{"type":"object","properties":{"forProvider":{"type":"object","properties":{"region":{"type":"string"}}}}}}}`),
+						},
+					},
+				},
+			},
+			want: want{
+				rsp: &fnv1.RunFunctionResponse{
+					Meta: &fnv1.ResponseMeta{Ttl: durationpb.New(response.DefaultTTL)},
+					Results: []*fnv1.Result{
+						{
+							Severity: fnv1.Severity_SEVERITY_FATAL,
+							Message:  `invalid composed resources: document 1 (Bucket "cool-logs", resource name "logs"): spec.forProvider.tags: unknown field`,
+							Target:   fnv1.Target_TARGET_COMPOSITE.Enum(),
+						},
+					},
+				},
+			},
+		},
+		"AggregateErrors": {
+			reason: "The Function should return all problems with rendered documents in the Aggregate error mode.",
+			args: args{
+				req: &fnv1.RunFunctionRequest{
+					Input: resource.MustStructObject(
+						&v1beta1.GoTemplate{
+							Source:    v1beta1.InlineSource,
+							Inline:    &v1beta1.TemplateSourceInline{Template: docsWithErrors},
+							ErrorMode: v1beta1.AggregateErrorMode,
+						}),
+					Observed: &fnv1.State{
+						Composite: &fnv1.Resource{
+							Resource: resource.MustStructJSON(xr),
+						},
+					},
+				},
+			},
+			want: want{
+				rsp: &fnv1.RunFunctionResponse{
+					Meta: &fnv1.ResponseMeta{Ttl: durationpb.New(response.DefaultTTL)},
+					Results: []*fnv1.Result{
+						{
+							Severity: fnv1.Severity_SEVERITY_WARNING,
+							Message:  "document 1 (CD \"no-name\"): \"CD\" template is missing required \"gotemplating.fn.crossplane.io/composition-resource-name\" annotation",
+							Target:   fnv1.Target_TARGET_COMPOSITE.Enum(),
+						},
+						{
+							Severity: fnv1.Severity_SEVERITY_WARNING,
+							Message:  "document 2: cannot decode manifest: error converting YAML to JSON: yaml: line 9 (document 2, line 4) near: 'name: [broken': did not find expected ',' or ']'",
+							Target:   fnv1.Target_TARGET_COMPOSITE.Enum(),
+						},
+						{
+							Severity: fnv1.Severity_SEVERITY_WARNING,
+							Message:  "document 3 (InvalidMeta): invalid kind \"InvalidMeta\" for apiVersion \"meta.gotemplating.fn.crossplane.io/v1alpha1\" - must be one of CompositeConnectionDetails, Context, ExtraResources or OperationOutput",
+							Target:   fnv1.Target_TARGET_COMPOSITE.Enum(),
+						},
+						{
+							Severity: fnv1.Severity_SEVERITY_FATAL,
+							Message:  "cannot compose resources, found 3 problems in rendered documents: document 1 (CD \"no-name\"): \"CD\" template is missing required \"gotemplating.fn.crossplane.io/composition-resource-name\" annotation; document 2: cannot decode manifest: error converting YAML to JSON: yaml: line 9 (document 2, line 4) near: 'name: [broken': did not find expected ',' or ']'; document 3 (InvalidMeta): invalid kind \"InvalidMeta\" for apiVersion \"meta.gotemplating.fn.crossplane.io/v1alpha1\" - must be one of CompositeConnectionDetails, Context, ExtraResources or OperationOutput",
+							Target:   fnv1.Target_TARGET_COMPOSITE.Enum(),
+						},
+					},
+				},
+			},
+		},
+		"SkipDocument": {
+			reason: "The Function should skip invalid documents with a warning and keep the observed version of their composed resources.",
+			args: args{
+				req: &fnv1.RunFunctionRequest{
+					Input: resource.MustStructObject(
+						&v1beta1.GoTemplate{
+							Source:        v1beta1.InlineSource,
+							Inline:        &v1beta1.TemplateSourceInline{Template: docsWithInvalidBucket},
+							FailurePolicy: v1beta1.SkipDocumentFailurePolicy,
+						}),
+					Observed: &fnv1.State{
+						Composite: &fnv1.Resource{
+							Resource: resource.MustStructJSON(xr),
+						},
+						Resources: map[string]*fnv1.Resource{
+							"logs": {
+								Resource: resource.MustStructJSON(`{"apiVersion":"example.org/v1","kind":"Bucket","metadata":{"name":"cool-logs","uid":"1234","resourceVersion":"42"},"spec":{"region":"eu-west-1"},"status":{"ready":true}}`),
+							},
+						},
+					},
+				},
+			},
+			want: want{
+				rsp: &fnv1.RunFunctionResponse{
+					Meta: &fnv1.ResponseMeta{Ttl: durationpb.New(response.DefaultTTL)},
+					Results: []*fnv1.Result{
+						{
+							Severity: fnv1.Severity_SEVERITY_WARNING,
+							Message:  "skipping invalid document: document 1: cannot decode manifest: error converting YAML to JSON: yaml: line 6 (document 1, line 6) near: 'name: [broken': did not find expected ',' or ']'",
+							Target:   fnv1.Target_TARGET_COMPOSITE.Enum(),
+						},
+						{
+							Severity: fnv1.Severity_SEVERITY_WARNING,
+							Message:  `skipping invalid document: document 2 (Bucket "no-name"): "Bucket" template is missing required "gotemplating.fn.crossplane.io/composition-resource-name" annotation`,
+							Target:   fnv1.Target_TARGET_COMPOSITE.Enum(),
+						},
+					},
+					Desired: &fnv1.State{
+						Composite: &fnv1.Resource{
+							Resource: resource.MustStructJSON(`{}`),
+						},
+						Resources: map[string]*fnv1.Resource{
+							"logs": {
+								Resource: resource.MustStructJSON(`{"apiVersion":"example.org/v1","kind":"Bucket","metadata":{"name":"cool-logs"},"spec":{"region":"eu-west-1"}}`),
+							},
+							"cool-cd": {
+								Resource: resource.MustStructJSON(`{"apiVersion":"example.org/v1","kind":"CD","metadata":{"annotations":{},"name":"cool-cd"}}`),
+							},
+						},
+					},
+				},
+			},
+		},
+		"SkipDocumentUnknownName": {
+			reason: "The Function should keep all observed composed resources that aren't desired if the name of a skipped document is unknown.",
+			args: args{
+				req: &fnv1.RunFunctionRequest{
+					Input: resource.MustStructObject(
+						&v1beta1.GoTemplate{
+							Source:        v1beta1.InlineSource,
+							Inline:        &v1beta1.TemplateSourceInline{Template: docsWithInvalidBucket},
+							FailurePolicy: v1beta1.SkipDocumentFailurePolicy,
+						}),
+					Observed: &fnv1.State{
+						Composite: &fnv1.Resource{
+							Resource: resource.MustStructJSON(xr),
+						},
+						Resources: map[string]*fnv1.Resource{
+							"no-name": {
+								Resource: resource.MustStructJSON(`{"apiVersion":"example.org/v1","kind":"Bucket","metadata":{"name":"no-name","uid":"5678"},"status":{"ready":true}}`),
+							},
+						},
+					},
+				},
+			},
+			want: want{
+				rsp: &fnv1.RunFunctionResponse{
+					Meta: &fnv1.ResponseMeta{Ttl: durationpb.New(response.DefaultTTL)},
+					Results: []*fnv1.Result{
+						{
+							Severity: fnv1.Severity_SEVERITY_WARNING,
+							Message:  "skipping invalid document: document 1: cannot decode manifest: error converting YAML to JSON: yaml: line 6 (document 1, line 6) near: 'name: [broken': did not find expected ',' or ']'",
+							Target:   fnv1.Target_TARGET_COMPOSITE.Enum(),
+						},
+						{
+							Severity: fnv1.Severity_SEVERITY_WARNING,
+							Message:  `skipping invalid document: document 2 (Bucket "no-name"): "Bucket" template is missing required "gotemplating.fn.crossplane.io/composition-resource-name" annotation`,
+							Target:   fnv1.Target_TARGET_COMPOSITE.Enum(),
+						},
+					},
+					Desired: &fnv1.State{
+						Composite: &fnv1.Resource{
+							Resource: resource.MustStructJSON(`{}`),
+						},
+						Resources: map[string]*fnv1.Resource{
+							"no-name": {
+								Resource: resource.MustStructJSON(`{"apiVersion":"example.org/v1","kind":"Bucket","metadata":{"name":"no-name"}}`),
+							},
+							"cool-cd": {
+								Resource: resource.MustStructJSON(`{"apiVersion":"example.org/v1","kind":"CD","metadata":{"annotations":{},"name":"cool-cd"}}`),
+							},
+						},
+					},
+				},
+			},
+		},
+		"PerDocumentExecution": {
+			reason: "The Function should execute each template on its own, and skip the templates that fail with a warning.",
+			args: args{
+				req: &fnv1.RunFunctionRequest{
+					Input: resource.MustStructObject(
+						&v1beta1.GoTemplate{
+							Source: v1beta1.InlineSource,
+							Inline: &v1beta1.TemplateSourceInline{Templates: []string{
+								bucketFailTmpl,
+								`{{ include "cd" . }}`,
+								`{{ define "cd" }}` + cdTmpl + `{{ end }}`,
+							}},
+							ExecutionMode: v1beta1.PerDocumentExecutionMode,
+							FailurePolicy: v1beta1.SkipDocumentFailurePolicy,
+						}),
+					Observed: &fnv1.State{
+						Composite: &fnv1.Resource{
+							Resource: resource.MustStructJSON(xr),
+						},
+						Resources: map[string]*fnv1.Resource{
+							"logs": {
+								Resource: resource.MustStructJSON(`{"apiVersion":"example.org/v1","kind":"Bucket","metadata":{"name":"cool-logs"},"spec":{"region":"eu-west-1"}}`),
+							},
+						},
+					},
+				},
+			},
+			want: want{
+				rsp: &fnv1.RunFunctionResponse{
+					Meta: &fnv1.ResponseMeta{Ttl: durationpb.New(response.DefaultTTL)},
+					Results: []*fnv1.Result{
+						{
+							Severity: fnv1.Severity_SEVERITY_WARNING,
+							Message:  `skipping invalid document: cannot execute template templates[0]: template: templates[0]:6:11: executing "templates[0]" at <fail "no region">: error calling fail: no region`,
+							Target:   fnv1.Target_TARGET_COMPOSITE.Enum(),
+						},
+					},
+					Desired: &fnv1.State{
+						Composite: &fnv1.Resource{
+							Resource: resource.MustStructJSON(`{}`),
+						},
+						Resources: map[string]*fnv1.Resource{
+							"logs": {
+								Resource: resource.MustStructJSON(`{"apiVersion":"example.org/v1","kind":"Bucket","metadata":{"name":"cool-logs"},"spec":{"region":"eu-west-1"}}`),
+							},
+							"cool-cd": {
+								Resource: resource.MustStructJSON(`{"apiVersion":"example.org/v1","kind":"CD","metadata":{"annotations":{},"name":"cool-cd","labels":{"belongsTo":"cool-xr"}}}`),
+							},
+						},
+					},
+				},
+			},
+		},
+		"PerDocumentExecutionUnknownName": {
+			reason: "The Function should keep the observed composed resources if the names of a skipped template are unknown.",
+			args: args{
+				req: &fnv1.RunFunctionRequest{
+					Input: resource.MustStructObject(
+						&v1beta1.GoTemplate{
+							Source:        v1beta1.InlineSource,
+							Inline:        &v1beta1.TemplateSourceInline{Templates: []string{bucketFailNameAnnotationTmpl}},
+							ExecutionMode: v1beta1.PerDocumentExecutionMode,
+							FailurePolicy: v1beta1.SkipDocumentFailurePolicy,
+						}),
+					Observed: &fnv1.State{
+						Composite: &fnv1.Resource{
+							Resource: resource.MustStructJSON(xr),
+						},
+						Resources: map[string]*fnv1.Resource{
+							"a": {
+								Resource: resource.MustStructJSON(`{"apiVersion":"example.org/v1","kind":"Bucket","metadata":{"name":"cool-a"}}`),
+							},
+						},
+					},
+				},
+			},
+			want: want{
+				rsp: &fnv1.RunFunctionResponse{
+					Meta: &fnv1.ResponseMeta{Ttl: durationpb.New(response.DefaultTTL)},
+					Results: []*fnv1.Result{
+						{
+							Severity: fnv1.Severity_SEVERITY_WARNING,
+							Message:  `skipping invalid document: cannot execute template templates[0]: template: templates[0]:6:11: executing "templates[0]" at <fail "boom">: error calling fail: boom`,
+							Target:   fnv1.Target_TARGET_COMPOSITE.Enum(),
+						},
+					},
+					Desired: &fnv1.State{
+						Composite: &fnv1.Resource{
+							Resource: resource.MustStructJSON(`{}`),
+						},
+						Resources: map[string]*fnv1.Resource{
+							"a": {
+								Resource: resource.MustStructJSON(`{"apiVersion":"example.org/v1","kind":"Bucket","metadata":{"name":"cool-a"}}`),
+							},
+						},
+					},
+				},
+			},
+		},
+		"PerDocumentExecutionFailFast": {
+			reason: "The Function should return a fatal result naming the template that fails in the FailFast error mode.",
+			args: args{
+				req: &fnv1.RunFunctionRequest{
+					Input: resource.MustStructObject(
+						&v1beta1.GoTemplate{
+							Source:        v1beta1.InlineSource,
+							Inline:        &v1beta1.TemplateSourceInline{Templates: []string{cdTmpl, bucketFailTmpl}},
+							ExecutionMode: v1beta1.PerDocumentExecutionMode,
+						}),
+					Observed: &fnv1.State{
+						Composite: &fnv1.Resource{
+							Resource: resource.MustStructJSON(xr),
+						},
+					},
+				},
+			},
+			want: want{
+				rsp: &fnv1.RunFunctionResponse{
+					Meta: &fnv1.ResponseMeta{Ttl: durationpb.New(response.DefaultTTL)},
+					Results: []*fnv1.Result{
+						{
+							Severity: fnv1.Severity_SEVERITY_FATAL,
+							Message:  `cannot execute template templates[1]: template: templates[1]:6:11: executing "templates[1]" at <fail "no region">: error calling fail: no region`,
+							Target:   fnv1.Target_TARGET_COMPOSITE.Enum(),
+						},
+					},
+				},
+			},
+		},
+		"Diff": {
+			reason: "The Function should return a Normal result with the changes of each rendered or deleted composed resource, but not of those of previous functions.",
+			args: args{
+				req: &fnv1.RunFunctionRequest{
+					Input: resource.MustStructObject(
+						&v1beta1.GoTemplate{
+							Source: v1beta1.InlineSource,
+							Inline: &v1beta1.TemplateSourceInline{Template: cdTmpl},
+							Diff:   &v1beta1.Diff{Output: v1beta1.ResultDiffOutput},
+						}),
+					Observed: &fnv1.State{
+						Composite: &fnv1.Resource{
+							Resource: resource.MustStructJSON(xr),
+						},
+						Resources: map[string]*fnv1.Resource{
+							"cool-cd": {
+								Resource: resource.MustStructJSON(`{"apiVersion":"example.org/v1","kind":"CD","metadata":{"name":"cool-cd","labels":{"belongsTo":"old-xr"},"uid":"1234"},"status":{"ready":true}}`),
+							},
+							"old-cd": {
+								Resource: resource.MustStructJSON(`{"apiVersion":"example.org/v1","kind":"CD","metadata":{"name":"old-cd"}}`),
+							},
+						},
+					},
+					Desired: &fnv1.State{
+						Resources: map[string]*fnv1.Resource{
+							"previous-cd": {
+								Resource: resource.MustStructJSON(`{"apiVersion":"example.org/v1","kind":"CD","metadata":{"name":"previous-cd"}}`),
+							},
+						},
+					},
+				},
+			},
+			want: want{
+				rsp: &fnv1.RunFunctionResponse{
+					Meta: &fnv1.ResponseMeta{Ttl: durationpb.New(response.DefaultTTL)},
+					Results: []*fnv1.Result{
+						{
+							Severity: fnv1.Severity_SEVERITY_NORMAL,
+							Message:  `composed resource "cool-cd" changes compared to desired: added; compared to observed: metadata.annotations: added {}, metadata.labels.belongsTo: "old-xr" -> "cool-xr"`,
+							Target:   fnv1.Target_TARGET_COMPOSITE.Enum(),
+						},
+						{
+							Severity: fnv1.Severity_SEVERITY_NORMAL,
+							Message:  `composed resource "old-cd" changes compared to observed: deleted`,
+							Target:   fnv1.Target_TARGET_COMPOSITE.Enum(),
+						},
+					},
+					Desired: &fnv1.State{
+						Composite: &fnv1.Resource{
+							Resource: resource.MustStructJSON(`{}`),
+						},
+						Resources: map[string]*fnv1.Resource{
+							"cool-cd": {
+								Resource: resource.MustStructJSON(`{"apiVersion":"example.org/v1","kind":"CD","metadata":{"annotations":{},"name":"cool-cd","labels":{"belongsTo":"cool-xr"}}}`),
+							},
+							"previous-cd": {
+								Resource: resource.MustStructJSON(`{"apiVersion":"example.org/v1","kind":"CD","metadata":{"name":"previous-cd"}}`),
+							},
+						},
+					},
+				},
+			},
+		},
 		"CustomInputTtl": {
 			reason: "The Function should use a custom TTL when instructed.",
 			args: args{
@@ -1971,21 +2983,21 @@ func TestRunFunction(t *testing.T) {
 
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
-			f := &Function{
+			f := &Renderer{
 				log:            logging.NewNopLogger(),
 				fsys:           testdataFS,
 				ttl:            response.DefaultTTL,
 				defaultSource:  tc.args.defaultSource,
 				defaultOptions: tc.args.defaultOptions,
 			}
-			rsp, err := f.RunFunction(tc.args.ctx, tc.args.req)
+			rsp, err := f.Render(tc.args.ctx, tc.args.req)
 
 			if diff := cmp.Diff(tc.want.rsp, rsp, protocmp.Transform()); diff != "" {
-				t.Errorf("%s\nf.RunFunction(...): -want rsp, +got rsp:\n%s", tc.reason, diff)
+				t.Errorf("%s\nf.Render(...): -want rsp, +got rsp:\n%s", tc.reason, diff)
 			}
 
 			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
-				t.Errorf("%s\nf.RunFunction(...): -want err, +got err:\n%s", tc.reason, diff)
+				t.Errorf("%s\nf.Render(...): -want err, +got err:\n%s", tc.reason, diff)
 			}
 		})
 	}
@@ -0,0 +1,1032 @@
+package render
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	stdjson "encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"math/rand"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+	"unicode/utf16"
+
+	sprig "github.com/Masterminds/sprig/v3"
+	"github.com/crossplane-contrib/function-go-templating/input/v1beta1"
+	"github.com/crossplane/crossplane-runtime/v2/pkg/fieldpath"
+	xpv2 "github.com/crossplane/crossplane/apis/v2/core/v2"
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/ini.v1"
+	"gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/json"
+
+	"github.com/crossplane/function-sdk-go/errors"
+)
+
+const recursionMaxNums = 1000
+
+func getFunctions() []template.FuncMap {
+	funcs := []template.FuncMap{
+		{
+			"randomChoice":                 randomChoice,
+			"stableChoice":                 stableChoice,
+			"stableRandAlphaNum":           stableRandAlphaNum,
+			"stableShuffle":                stableShuffle,
+			"stableInt":                    stableInt,
+			"toYaml":                       toYaml,
+			"toYamlIndent":                 toYamlIndent,
+			"toToml":                       toToml,
+			"fromToml":                     fromToml,
+			"toIni":                        toIni,
+			"fromIni":                      fromIni,
+			"toProperties":                 toProperties,
+			"toHcl":                        toHcl,
+			"fromYaml":                     fromYaml,
+			"fromYamlArray":                fromYamlArray,
+			"fromYamlAll":                  fromYamlAll,
+			"toJsonStrict":                 toJSON,
+			"fromJsonStrict":               fromJSON,
+			"getResourceCondition":         getResourceCondition,
+			"isReady":                      isReady,
+			"isSynced":                     isSynced,
+			"allReady":                     allReady,
+			"readyCount":                   readyCount,
+			"setResourceNameAnnotation":    setResourceNameAnnotation,
+			"getComposedResource":          getComposedResource,
+			"getCompositeResource":         getCompositeResource,
+			"isOperation":                  isOperationRequest,
+			"getWatchedResource":           getWatchedResource,
+			"getDesiredComposedResource":   getDesiredComposedResource,
+			"getDesiredCompositeResource":  getDesiredCompositeResource,
+			"listComposedResources":        listComposedResources,
+			"getComposedConnectionDetails": getComposedConnectionDetails,
+			"getExtraResources":            getExtraResources,
+			"getExtraResource":             getExtraResource,
+			"findExtraResource":            findExtraResource,
+			"extraResourcesByLabel":        extraResourcesByLabel,
+			"getExtraResourcesFromContext": getExtraResourcesFromContext,
+			"getConnectionDetail":          getConnectionDetail,
+			"getCredentialData":            getCredentialData,
+			"getCredentialValue":           getCredentialValue,
+			"getCredentials":               getCredentials,
+			"toSecretData":                 toSecretData,
+			"fromSecretData":               fromSecretData,
+			"getField":                     getField,
+			"getFieldOr":                   getFieldOr,
+			"hasField":                     hasField,
+			"jq":                           jq,
+			"jsonpath":                     jsonPath,
+			"k8sName":                      k8sName,
+			"k8sLabelValue":                k8sLabelValue,
+			"s3BucketName":                 s3BucketName,
+			"azureStorageAccountName":      azureStorageAccountName,
+			"cidrsubnet":                   cidrsubnet,
+			"cidrsubnets":                  cidrsubnets,
+			"cidrhost":                     cidrhost,
+			"cidrnetmask":                  cidrnetmask,
+			"cidrcontains":                 cidrcontains,
+			"cidroverlaps":                 cidroverlaps,
+			"quantityAdd":                  quantityAdd,
+			"quantitySum":                  quantitySum,
+			"quantitySub":                  quantitySub,
+			"quantityMul":                  quantityMul,
+			"quantityCmp":                  quantityCmp,
+			"quantityConvert":              quantityConvert,
+			"durationAdd":                  durationAdd,
+			"durationSub":                  durationSub,
+			"durationMul":                  durationMul,
+			"durationCmp":                  durationCmp,
+			"durationSeconds":              durationSeconds,
+			"durationToISO8601":            durationToISO8601,
+			"hmacSha256":                   hmacSha256,
+			"deriveHmac":                   deriveHmac,
+			"stablePassword":               stablePassword,
+			"stableBcrypt":                 stableBcrypt,
+			"stableHtpasswd":               stableHtpasswd,
+			"pemFingerprint":               pemFingerprint,
+			"parseCertificate":             parseCertificate,
+			"parseCertificates":            parseCertificates,
+		},
+	}
+
+	return funcs
+}
+
+// GetNewTemplateWithFunctionMaps returns a new template with the supplied
+// delimiters, Sprig's functions, the functions of this package and the
+// functions of the registered extensions. The supplied function maps are added
+// last, so they take precedence over all other functions, followed by the
+// functions of extensions. The credential functions need the credentials of
+// the request, so the Renderer supplies them.
+func GetNewTemplateWithFunctionMaps(delims *v1beta1.Delims, funcs ...template.FuncMap) *template.Template {
+	tpl := template.New("manifests")
+
+	if delims != nil {
+		if delims.Left != nil && delims.Right != nil {
+			tpl = tpl.Delims(*delims.Left, *delims.Right)
+		}
+	}
+
+	// Sprig's env and expandenv can lead to information leakage (injected tokens/passwords).
+	// Both Helm and ArgoCD remove these due to security implications.
+	// see: https://masterminds.github.io/sprig/os.html
+	sprigFuncs := sprig.FuncMap()
+	delete(sprigFuncs, "env")
+	delete(sprigFuncs, "expandenv")
+	tpl.Funcs(sprigFuncs)
+
+	// None of our functions has the name of a Sprig function, so that Sprig's
+	// functions keep their behaviour.
+	for _, f := range getFunctions() {
+		tpl.Funcs(f)
+	}
+	tpl.Funcs(template.FuncMap{
+		"include": initInclude(tpl),
+		"tpl":     initTpl(tpl),
+	})
+
+	// Functions of extensions and the supplied functions are added last, so
+	// that they take precedence over the built-in functions.
+	for _, f := range registeredFunctions() {
+		tpl.Funcs(f)
+	}
+	for _, f := range funcs {
+		tpl.Funcs(f)
+	}
+
+	return tpl
+}
+
+func randomChoice(choices ...string) string {
+	r := rand.New(rand.NewSource(time.Now().UnixNano())) //nolint:gosec // strong random number generation is not required
+
+	return choices[r.Intn(len(choices))]
+}
+
+// stableRand returns a random number generator seeded by the identity of the
+// observed composite resource and the supplied salt. It yields the same
+// sequence on every reconcile of the same composite resource.
+func stableRand(req map[string]any, salt string) *rand.Rand {
+	sum := sha256.Sum256([]byte(compositeIdentity(req) + "/" + salt))
+	seed := int64(binary.BigEndian.Uint64(sum[:8])) //nolint:gosec // overflow is fine, we only need a seed
+
+	return rand.New(rand.NewSource(seed)) //nolint:gosec // strong random number generation is not required
+}
+
+// compositeIdentity returns the UID of the observed composite resource. It
+// falls back to the composite's kind, namespace and name if no UID is set,
+// for example when rendering locally.
+func compositeIdentity(req map[string]any) string {
+	xr := fieldpath.Pave(getCompositeResource(req))
+	if uid, err := xr.GetString("metadata.uid"); err == nil && uid != "" {
+		return uid
+	}
+
+	kind, _ := xr.GetString("kind")
+	namespace, _ := xr.GetString("metadata.namespace")
+	name, _ := xr.GetString("metadata.name")
+
+	return fmt.Sprintf("%s/%s/%s", kind, namespace, name)
+}
+
+func stableChoice(req map[string]any, salt string, choices ...string) (string, error) {
+	if len(choices) == 0 {
+		return "", errors.New("stableChoice requires at least one choice")
+	}
+
+	return choices[stableRand(req, salt).Intn(len(choices))], nil
+}
+
+func stableRandAlphaNum(req map[string]any, salt string, n int) (string, error) {
+	if n < 1 {
+		return "", errors.Errorf("stableRandAlphaNum requires a length of at least 1, got %d", n)
+	}
+
+	const alphaNum = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+	r := stableRand(req, salt)
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = alphaNum[r.Intn(len(alphaNum))]
+	}
+
+	return string(b), nil
+}
+
+func stableShuffle(req map[string]any, salt string, list any) ([]any, error) {
+	v := reflect.ValueOf(list)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil, errors.Errorf("stableShuffle requires a list, got %T", list)
+	}
+
+	res := make([]any, v.Len())
+	for i := range res {
+		res[i] = v.Index(i).Interface()
+	}
+	stableRand(req, salt).Shuffle(len(res), func(i, j int) {
+		res[i], res[j] = res[j], res[i]
+	})
+
+	return res, nil
+}
+
+func stableInt(req map[string]any, salt string, minVal, maxVal int) (int, error) {
+	if maxVal <= minVal {
+		return 0, errors.Errorf("stableInt requires max (%d) to be greater than min (%d)", maxVal, minVal)
+	}
+
+	return minVal + stableRand(req, salt).Intn(maxVal-minVal), nil
+}
+
+func toYaml(val any) (string, error) {
+	res, err := yaml.Marshal(val)
+	if err != nil {
+		return "", err
+	}
+
+	return string(res), nil
+}
+
+// toYamlIndent marshals val to YAML using the supplied indent, which must be
+// between 2 and 9 spaces. The value comes last so it can be used in pipelines.
+func toYamlIndent(indent int, val any) (string, error) {
+	if indent < 2 || indent > 9 {
+		return "", errors.Errorf("invalid YAML indent %d: must be between 2 and 9", indent)
+	}
+
+	buf := &bytes.Buffer{}
+	enc := yaml.NewEncoder(buf)
+	enc.SetIndent(indent)
+	if err := enc.Encode(val); err != nil {
+		return "", err
+	}
+	if err := enc.Close(); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+func fromYaml(val string) (any, error) {
+	var res any
+	err := yaml.Unmarshal([]byte(val), &res)
+
+	return res, err
+}
+
+// fromYamlArray unmarshals a YAML string that contains a list.
+func fromYamlArray(val string) ([]any, error) {
+	var res []any
+	if err := yaml.Unmarshal([]byte(val), &res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// fromYamlAll unmarshals a YAML string that contains multiple documents,
+// separated by ---. Empty documents are skipped.
+func fromYamlAll(val string) ([]any, error) {
+	res := make([]any, 0)
+	dec := yaml.NewDecoder(strings.NewReader(val))
+	for {
+		var doc any
+		err := dec.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot unmarshal YAML document %d", len(res)+1)
+		}
+		if doc != nil {
+			res = append(res, doc)
+		}
+	}
+
+	return res, nil
+}
+
+func toToml(val any) (string, error) {
+	res, err := toml.Marshal(val)
+	if err != nil {
+		return "", errors.Wrapf(err, "cannot marshal %T to TOML", val)
+	}
+
+	return string(res), nil
+}
+
+func fromToml(val string) (map[string]any, error) {
+	res := make(map[string]any)
+	if err := toml.Unmarshal([]byte(val), &res); err != nil {
+		return nil, errors.Wrap(err, "cannot unmarshal TOML")
+	}
+
+	return res, nil
+}
+
+// toIni marshals a map to INI. Keys with map values become sections, all other
+// keys are written before the first section.
+func toIni(val map[string]any) (string, error) {
+	cfg := ini.Empty()
+	for _, k := range slices.Sorted(maps.Keys(val)) {
+		section, isSection := val[k].(map[string]any)
+		if !isSection {
+			if _, err := cfg.Section(ini.DefaultSection).NewKey(k, fmt.Sprint(val[k])); err != nil {
+				return "", errors.Wrapf(err, "cannot add INI key %q", k)
+			}
+			continue
+		}
+
+		sec, err := cfg.NewSection(k)
+		if err != nil {
+			return "", errors.Wrapf(err, "cannot add INI section %q", k)
+		}
+		for _, sk := range slices.Sorted(maps.Keys(section)) {
+			if _, ok := section[sk].(map[string]any); ok {
+				return "", errors.Errorf("cannot marshal INI key %q in section %q: INI does not support nested sections", sk, k)
+			}
+			if _, err := sec.NewKey(sk, fmt.Sprint(section[sk])); err != nil {
+				return "", errors.Wrapf(err, "cannot add INI key %q to section %q", sk, k)
+			}
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	if _, err := cfg.WriteTo(buf); err != nil {
+		return "", errors.Wrap(err, "cannot marshal INI")
+	}
+
+	return buf.String(), nil
+}
+
+// fromIni unmarshals an INI string. Keys before the first section are returned
+// at the top level, sections are returned as maps of strings.
+func fromIni(val string) (map[string]any, error) {
+	cfg, err := ini.Load([]byte(val))
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot unmarshal INI")
+	}
+
+	res := make(map[string]any)
+	for _, sec := range cfg.Sections() {
+		keys := make(map[string]any)
+		for k, v := range sec.KeysHash() {
+			keys[k] = v
+		}
+		if sec.Name() == ini.DefaultSection {
+			maps.Copy(res, keys)
+			continue
+		}
+		res[sec.Name()] = keys
+	}
+
+	return res, nil
+}
+
+// toProperties marshals a map to Java properties. Nested maps are flattened
+// into dot-separated keys and lists into indexed keys, e.g. a.b[0]=c.
+func toProperties(val map[string]any) (string, error) {
+	props := make(map[string]string)
+	if err := flattenProperties("", val, props); err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	for _, k := range slices.Sorted(maps.Keys(props)) {
+		b.WriteString(escapeProperty(k, true))
+		b.WriteString("=")
+		b.WriteString(escapeProperty(props[k], false))
+		b.WriteString("\n")
+	}
+
+	return b.String(), nil
+}
+
+func flattenProperties(prefix string, val any, props map[string]string) error {
+	switch v := val.(type) {
+	case map[string]any:
+		for k, e := range v {
+			key := k
+			if prefix != "" {
+				key = prefix + "." + k
+			}
+			if err := flattenProperties(key, e, props); err != nil {
+				return err
+			}
+		}
+	case []any:
+		for i, e := range v {
+			if err := flattenProperties(fmt.Sprintf("%s[%d]", prefix, i), e, props); err != nil {
+				return err
+			}
+		}
+	case nil:
+		props[prefix] = ""
+	default:
+		if prefix == "" {
+			return errors.Errorf("cannot marshal %T to properties: must be a map", val)
+		}
+		props[prefix] = fmt.Sprint(v)
+	}
+
+	return nil
+}
+
+// escapeProperty escapes a Java properties key or value. Characters outside
+// of printable ASCII are written as unicode escapes.
+func escapeProperty(s string, isKey bool) string {
+	var b strings.Builder
+	for i, r := range s {
+		switch {
+		case r == '\\':
+			b.WriteString(`\\`)
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\r':
+			b.WriteString(`\r`)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case r == '\f':
+			b.WriteString(`\f`)
+		case r == ' ' && (isKey || i == 0):
+			b.WriteString(`\ `)
+		case isKey && strings.ContainsRune("=:#!", r):
+			b.WriteRune('\\')
+			b.WriteRune(r)
+		case r < 0x20 || r > 0x7e:
+			for _, u := range utf16.Encode([]rune{r}) {
+				fmt.Fprintf(&b, `\u%04x`, u)
+			}
+		default:
+			b.WriteRune(r)
+		}
+	}
+
+	return b.String()
+}
+
+// toHcl marshals a map to HCL attributes, like a Terraform .tfvars file.
+func toHcl(val map[string]any) (string, error) {
+	var b strings.Builder
+	for _, k := range slices.Sorted(maps.Keys(val)) {
+		if !hclIdentifier.MatchString(k) {
+			return "", errors.Errorf("cannot marshal HCL attribute %q: must be a valid identifier", k)
+		}
+		b.WriteString(k)
+		b.WriteString(" = ")
+		if err := writeHclValue(&b, val[k], ""); err != nil {
+			return "", errors.Wrapf(err, "cannot marshal HCL attribute %q", k)
+		}
+		b.WriteString("\n")
+	}
+
+	return b.String(), nil
+}
+
+var hclIdentifier = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_-]*$`)
+
+func writeHclValue(b *strings.Builder, val any, indent string) error {
+	switch v := val.(type) {
+	case nil:
+		b.WriteString("null")
+	case bool, int, int32, int64, float32, float64:
+		fmt.Fprint(b, v)
+	case string:
+		b.WriteString(quoteHclString(v))
+	case []any:
+		if len(v) == 0 {
+			b.WriteString("[]")
+			return nil
+		}
+		b.WriteString("[\n")
+		for _, e := range v {
+			b.WriteString(indent + "  ")
+			if err := writeHclValue(b, e, indent+"  "); err != nil {
+				return err
+			}
+			b.WriteString(",\n")
+		}
+		b.WriteString(indent + "]")
+	case map[string]any:
+		if len(v) == 0 {
+			b.WriteString("{}")
+			return nil
+		}
+		b.WriteString("{\n")
+		for _, k := range slices.Sorted(maps.Keys(v)) {
+			b.WriteString(indent + "  ")
+			if hclIdentifier.MatchString(k) {
+				b.WriteString(k)
+			} else {
+				b.WriteString(quoteHclString(k))
+			}
+			b.WriteString(" = ")
+			if err := writeHclValue(b, v[k], indent+"  "); err != nil {
+				return err
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString(indent + "}")
+	default:
+		// Convert any other type, e.g. []string, to its JSON equivalent.
+		u, err := normalizeValue(v)
+		if err != nil {
+			return err
+		}
+		return writeHclValue(b, u, indent)
+	}
+
+	return nil
+}
+
+// quoteHclString quotes s as an HCL string literal. Template sequences are
+// escaped so that they are not interpolated.
+func quoteHclString(s string) string {
+	var b strings.Builder
+	b.WriteRune('"')
+	for i, r := range s {
+		switch {
+		case r == '"':
+			b.WriteString(`\"`)
+		case r == '\\':
+			b.WriteString(`\\`)
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\r':
+			b.WriteString(`\r`)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case r < 0x20 || r == 0x7f:
+			fmt.Fprintf(&b, `\u%04x`, r)
+		case (r == '$' || r == '%') && strings.HasPrefix(s[i+1:], "{"):
+			// Escape ${ and %{ as $${ and %%{.
+			b.WriteRune(r)
+			b.WriteRune(r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteRune('"')
+
+	return b.String()
+}
+
+// toJSON marshals val to JSON with object keys sorted and without escaping
+// HTML. Unlike Sprig's toJson it returns an error if val cannot be marshalled.
+func toJSON(val any) (string, error) {
+	j, err := json.Marshal(val)
+	if err != nil {
+		return "", errors.Wrapf(err, "cannot marshal %T to JSON", val)
+	}
+
+	// Round trip through an untyped value so that struct fields are sorted
+	// as well as map keys.
+	var u any
+	if err := json.Unmarshal(j, &u); err != nil {
+		return "", errors.Wrap(err, "cannot unmarshal JSON")
+	}
+
+	buf := &bytes.Buffer{}
+	enc := stdjson.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(u); err != nil {
+		return "", errors.Wrapf(err, "cannot marshal %T to JSON", val)
+	}
+
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+// fromJSON unmarshals a JSON string. Numbers are decoded the same way as in
+// the request, i.e. integers as int64 and all other numbers as float64. Unlike
+// Sprig's fromJson it returns an error if val is not valid JSON, including an
+// empty string.
+func fromJSON(val string) (any, error) {
+	var res any
+	if err := json.Unmarshal([]byte(val), &res); err != nil {
+		return nil, errors.Wrap(err, "cannot unmarshal JSON")
+	}
+
+	return res, nil
+}
+
+func getResourceCondition(ct string, res map[string]any) xpv2.Condition {
+	var conditioned xpv2.ConditionedStatus
+	if err := fieldpath.Pave(res).GetValueInto("resource.status", &conditioned); err != nil {
+		if err := fieldpath.Pave(res).GetValueInto("status", &conditioned); err != nil {
+			conditioned = xpv2.ConditionedStatus{}
+		}
+	}
+
+	// Return either found condition or empty one with "Unknown" status
+	return conditioned.GetCondition(xpv2.ConditionType(ct))
+}
+
+func setResourceNameAnnotation(name string) string {
+	return fmt.Sprintf("gotemplating.fn.crossplane.io/composition-resource-name: %s", name)
+}
+
+func initInclude(t *template.Template) func(string, any) (string, error) {
+	includedNames := make(map[string]int)
+
+	return func(name string, data any) (string, error) {
+		var buf strings.Builder
+		if v, ok := includedNames[name]; ok {
+			if v > recursionMaxNums {
+				return "", errors.Wrapf(fmt.Errorf("unable to execute template"), "rendering template has a nested reference name: %s", name)
+			}
+			includedNames[name]++
+		} else {
+			includedNames[name] = 1
+		}
+		err := t.ExecuteTemplate(&buf, name, data)
+		includedNames[name]--
+		return buf.String(), err
+	}
+}
+
+// initTpl returns a function that renders a string as a template against the
+// supplied data. The string shares delimiters, options, functions and defined
+// templates with t.
+func initTpl(t *template.Template) func(string, any) (string, error) {
+	renderedTexts := make(map[string]int)
+
+	return func(text string, data any) (string, error) {
+		if v, ok := renderedTexts[text]; ok {
+			if v > recursionMaxNums {
+				return "", errors.Wrapf(fmt.Errorf("unable to execute template"), "rendering template has a nested reference: %s", text)
+			}
+			renderedTexts[text]++
+		} else {
+			renderedTexts[text] = 1
+		}
+		defer func() { renderedTexts[text]-- }()
+
+		clone, err := t.Clone()
+		if err != nil {
+			return "", errors.Wrap(err, "cannot clone template")
+		}
+		nt, err := clone.New("tpl").Parse(text)
+		if err != nil {
+			return "", errors.Wrap(err, "cannot parse template")
+		}
+
+		var buf strings.Builder
+		if err := nt.Execute(&buf, data); err != nil {
+			return "", errors.Wrap(err, "cannot execute template")
+		}
+
+		return buf.String(), nil
+	}
+}
+
+func getComposedResource(req map[string]any, name string) map[string]any {
+	var cr map[string]any
+	path := fmt.Sprintf("observed.resources[%s]resource", name)
+	if err := fieldpath.Pave(req).GetValueInto(path, &cr); err != nil {
+		return nil
+	}
+
+	return cr
+}
+
+func getCompositeResource(req map[string]any) map[string]any {
+	var cr map[string]any
+	if err := fieldpath.Pave(req).GetValueInto("observed.composite.resource", &cr); err != nil {
+		return nil
+	}
+
+	return cr
+}
+
+func getDesiredComposedResource(req map[string]any, name string) map[string]any {
+	var cr map[string]any
+	path := fmt.Sprintf("desired.resources[%s]resource", name)
+	if err := fieldpath.Pave(req).GetValueInto(path, &cr); err != nil {
+		return nil
+	}
+
+	return cr
+}
+
+func getDesiredCompositeResource(req map[string]any) map[string]any {
+	var cr map[string]any
+	if err := fieldpath.Pave(req).GetValueInto("desired.composite.resource", &cr); err != nil {
+		return nil
+	}
+
+	return cr
+}
+
+// listComposedResources returns the observed or desired composed resources,
+// keyed by their composition resource name. An optional filter may select
+// resources by apiVersion, kind and labelSelector. The label selector is
+// either a map of labels or a string like "app=web,tier!=db".
+func listComposedResources(req map[string]any, state string, filter ...map[string]any) (map[string]any, error) {
+	if state != "observed" && state != "desired" {
+		return nil, errors.Errorf("invalid state %q: must be observed or desired", state)
+	}
+	if len(filter) > 1 {
+		return nil, errors.New("listComposedResources accepts at most one filter")
+	}
+
+	match := func(map[string]any) bool { return true }
+	if len(filter) == 1 {
+		m, err := composedResourceMatcher(filter[0])
+		if err != nil {
+			return nil, err
+		}
+		match = m
+	}
+
+	// Resources are missing if there are none, which is not an error.
+	var resources map[string]struct {
+		Resource map[string]any `json:"resource"`
+	}
+	_ = fieldpath.Pave(req).GetValueInto(state+".resources", &resources)
+
+	res := make(map[string]any)
+	for name, r := range resources {
+		if r.Resource != nil && match(r.Resource) {
+			res[name] = r.Resource
+		}
+	}
+
+	return res, nil
+}
+
+// composedResourceMatcher returns a function that reports whether a resource
+// matches the supplied filter.
+func composedResourceMatcher(filter map[string]any) (func(map[string]any) bool, error) {
+	for k := range filter {
+		if k != "apiVersion" && k != "kind" && k != "labelSelector" {
+			return nil, errors.Errorf("invalid filter field %q: must be one of apiVersion, kind or labelSelector", k)
+		}
+	}
+
+	sel, err := parseLabelSelector(filter["labelSelector"])
+	if err != nil {
+		return nil, err
+	}
+
+	return func(r map[string]any) bool {
+		return matchesFields(r, filter, "apiVersion", "kind") && matchesLabels(r, sel)
+	}, nil
+}
+
+// parseLabelSelector parses a label selector that is either a map of labels
+// or a string like "app=web,tier!=db". A nil selector matches everything.
+func parseLabelSelector(selector any) (labels.Selector, error) {
+	switch ls := selector.(type) {
+	case nil:
+		return labels.Everything(), nil
+	case string:
+		s, err := labels.Parse(ls)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid label selector %q", ls)
+		}
+		return s, nil
+	case map[string]any:
+		set := labels.Set{}
+		for k, v := range ls {
+			set[k] = fmt.Sprint(v)
+		}
+		s, err := labels.ValidatedSelectorFromSet(set)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid label selector")
+		}
+		return s, nil
+	default:
+		return nil, errors.Errorf("invalid label selector of type %T: must be a string or a map", ls)
+	}
+}
+
+// matchesFields reports whether the supplied fields of resource r have the
+// values in filter. Fields that are not in filter are ignored.
+func matchesFields(r, filter map[string]any, fields ...string) bool {
+	p := fieldpath.Pave(r)
+	for _, f := range fields {
+		want, ok := filter[f]
+		if !ok {
+			continue
+		}
+		if got, _ := p.GetString(f); got != fmt.Sprint(want) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// matchesLabels reports whether the labels of resource r match sel.
+func matchesLabels(r map[string]any, sel labels.Selector) bool {
+	return sel.Matches(labels.Set(resourceLabels(r)))
+}
+
+func resourceLabels(r map[string]any) map[string]string {
+	var l map[string]string
+	_ = fieldpath.Pave(r).GetValueInto("metadata.labels", &l)
+
+	return l
+}
+
+func getComposedConnectionDetails(req map[string]any, name string) map[string]any {
+	var cd map[string]any
+	path := fmt.Sprintf("observed.resources[%s]connectionDetails", name)
+	if err := fieldpath.Pave(req).GetValueInto(path, &cd); err != nil {
+		return nil
+	}
+
+	return cd
+}
+
+func getExtraResources(req map[string]any, name string) []any {
+	var ers []any
+	path := fmt.Sprintf("requiredResources[%s].items", name)
+	if err := fieldpath.Pave(req).GetValueInto(path, &ers); err != nil {
+		path := fmt.Sprintf("extraResources[%s].items", name)
+		if err := fieldpath.Pave(req).GetValueInto(path, &ers); err != nil {
+			return nil
+		}
+	}
+
+	return ers
+}
+
+// getExtraResource returns the only extra resource of the supplied
+// requirement. It returns nil if there is none, and an error if there is more
+// than one.
+func getExtraResource(req map[string]any, name string) (map[string]any, error) {
+	res := extraResourcesOf(req, name)
+	switch len(res) {
+	case 0:
+		return nil, nil
+	case 1:
+		return res[0], nil
+	default:
+		return nil, errors.Errorf("requirement %q matched %d extra resources, expected at most one", name, len(res))
+	}
+}
+
+// findExtraResource returns the extra resource of the supplied requirement
+// that matches filter. The filter may select by name, namespace and
+// labelSelector. It returns nil if no resource matches, and an error if more
+// than one does.
+func findExtraResource(req map[string]any, name string, filter map[string]any) (map[string]any, error) {
+	for k := range filter {
+		if k != "name" && k != "namespace" && k != "labelSelector" {
+			return nil, errors.Errorf("invalid filter field %q: must be one of name, namespace or labelSelector", k)
+		}
+	}
+	sel, err := parseLabelSelector(filter["labelSelector"])
+	if err != nil {
+		return nil, err
+	}
+
+	meta := make(map[string]any)
+	for _, k := range []string{"name", "namespace"} {
+		if v, ok := filter[k]; ok {
+			meta["metadata."+k] = v
+		}
+	}
+
+	var found []map[string]any
+	for _, r := range extraResourcesOf(req, name) {
+		if matchesFields(r, meta, "metadata.name", "metadata.namespace") && matchesLabels(r, sel) {
+			found = append(found, r)
+		}
+	}
+
+	switch len(found) {
+	case 0:
+		return nil, nil
+	case 1:
+		return found[0], nil
+	default:
+		return nil, errors.Errorf("filter matched %d extra resources of requirement %q, expected at most one", len(found), name)
+	}
+}
+
+// extraResourcesByLabel groups the extra resources of the supplied
+// requirement by the value of the supplied label. Resources without the
+// label are omitted.
+func extraResourcesByLabel(req map[string]any, name, label string) map[string]any {
+	res := make(map[string]any)
+	for _, r := range extraResourcesOf(req, name) {
+		v, ok := resourceLabels(r)[label]
+		if !ok {
+			continue
+		}
+		group, _ := res[v].([]any)
+		res[v] = append(group, r)
+	}
+
+	return res
+}
+
+// extraResourcesOf returns the extra resources of the supplied requirement,
+// without the wrapping items.
+func extraResourcesOf(req map[string]any, name string) []map[string]any {
+	items := getExtraResources(req, name)
+	res := make([]map[string]any, 0, len(items))
+	for _, i := range items {
+		item, _ := i.(map[string]any)
+		if r, ok := item["resource"].(map[string]any); ok {
+			res = append(res, r)
+		}
+	}
+
+	return res
+}
+
+func getExtraResourcesFromContext(req map[string]any, name string) []any {
+	var ers []any
+	path := fmt.Sprintf("context[%s][%s].items", extraResourcesContextKey, name)
+	if err := fieldpath.Pave(req).GetValueInto(path, &ers); err != nil {
+		return nil
+	}
+
+	return ers
+}
+
+// getField returns the value at the supplied field path of obj. It returns an
+// error if the field does not exist.
+func getField(obj any, path string) (any, error) {
+	p, err := paveObject(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := p.GetValue(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot get field %q", path)
+	}
+
+	return v, nil
+}
+
+// getFieldOr returns the value at the supplied field path of obj, or def if
+// the field does not exist.
+func getFieldOr(obj any, path string, def any) (any, error) {
+	p, err := paveObject(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := p.GetValue(path)
+	if fieldpath.IsNotFound(err) {
+		return def, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot get field %q", path)
+	}
+
+	return v, nil
+}
+
+// hasField returns true if the supplied field path exists in obj.
+func hasField(obj any, path string) (bool, error) {
+	p, err := paveObject(obj)
+	if err != nil {
+		return false, err
+	}
+
+	_, err = p.GetValue(path)
+	if fieldpath.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.Wrapf(err, "cannot get field %q", path)
+	}
+
+	return true, nil
+}
+
+// paveObject returns a fieldpath.Paved for obj. Objects that are not a
+// map[string]any are converted through JSON.
+func paveObject(obj any) (*fieldpath.Paved, error) {
+	switch o := obj.(type) {
+	case map[string]any:
+		return fieldpath.Pave(o), nil
+	case nil:
+		return fieldpath.Pave(map[string]any{}), nil
+	}
+
+	j, err := json.Marshal(obj)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot marshal %T to json", obj)
+	}
+
+	m := make(map[string]any)
+	if err := json.Unmarshal(j, &m); err != nil {
+		return nil, errors.Wrapf(err, "cannot convert %T to an object", obj)
+	}
+
+	return fieldpath.Pave(m), nil
+}
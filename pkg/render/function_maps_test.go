@@ -0,0 +1,2309 @@
+package render
+
+import (
+	"bytes"
+	"testing"
+	"text/template"
+
+	sprig "github.com/Masterminds/sprig/v3"
+	"github.com/crossplane-contrib/function-go-templating/input/v1beta1"
+	v2 "github.com/crossplane/crossplane/apis/v2/core/v2"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"google.golang.org/protobuf/testing/protocmp"
+	"k8s.io/utils/ptr"
+
+	fnv1 "github.com/crossplane/function-sdk-go/proto/v1"
+)
+
+func TestGetFunctionsSprigCollisions(t *testing.T) {
+	s := sprig.FuncMap()
+	for _, funcs := range getFunctions() {
+		for name := range funcs {
+			if _, ok := s[name]; ok {
+				t.Errorf("getFunctions(): function %q replaces Sprig's function of the same name", name)
+			}
+		}
+	}
+}
+
+func Test_fromYaml(t *testing.T) {
+	type args struct {
+		val string
+	}
+	type want struct {
+		rsp any
+		err error
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"UnmarshalYaml": {
+			reason: "Should return unmarshalled yaml",
+			args: args{
+				val: `
+complexDictionary:
+  scalar1: true
+  list:
+  - abc
+  - def`,
+			},
+			want: want{
+				rsp: map[string]any{
+					"complexDictionary": map[string]any{
+						"scalar1": true,
+						"list": []any{
+							"abc",
+							"def",
+						},
+					},
+				},
+			},
+		},
+		"UnmarshalYamlError": {
+			reason: "Should return error when unmarshalling yaml",
+			args: args{
+				val: `
+complexDictionary:
+	  scalar1: true
+`,
+			},
+			want: want{
+				err: cmpopts.AnyError,
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			rsp, err := fromYaml(tc.args.val)
+
+			if diff := cmp.Diff(tc.want.rsp, rsp, protocmp.Transform()); diff != "" {
+				t.Errorf("%s\nfromYaml(...): -want rsp, +got rsp:\n%s", tc.reason, diff)
+			}
+
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("%s\nfromYaml(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func Test_toYaml(t *testing.T) {
+	type args struct {
+		val any
+	}
+	type want struct {
+		rsp any
+		err error
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"MarshalYaml": {
+			reason: "Should return marshalled yaml",
+			args: args{
+				val: map[string]any{
+					"complexDictionary": map[string]any{
+						"scalar1": true,
+						"list": []any{
+							"abc",
+							"def",
+						},
+					},
+				},
+			},
+			want: want{
+				rsp: `complexDictionary:
+    list:
+        - abc
+        - def
+    scalar1: true
+`,
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			rsp, err := toYaml(tc.args.val)
+
+			if diff := cmp.Diff(tc.want.rsp, rsp, protocmp.Transform()); diff != "" {
+				t.Errorf("%s\ntoYaml(...): -want rsp, +got rsp:\n%s", tc.reason, diff)
+			}
+
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("%s\ntoYaml(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func Test_toYamlIndent(t *testing.T) {
+	type args struct {
+		indent int
+		val    any
+	}
+	type want struct {
+		rsp string
+		err error
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"MarshalYamlIndent": {
+			reason: "Should return marshalled yaml with the supplied indent",
+			args: args{
+				indent: 2,
+				val: map[string]any{
+					"complexDictionary": map[string]any{
+						"list": []any{
+							"abc",
+						},
+					},
+				},
+			},
+			want: want{
+				rsp: `complexDictionary:
+  list:
+    - abc
+`,
+			},
+		},
+		"InvalidIndent": {
+			reason: "Should return an error if the indent is out of range",
+			args: args{
+				indent: 1,
+				val:    map[string]any{"key": "value"},
+			},
+			want: want{
+				err: cmpopts.AnyError,
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			rsp, err := toYamlIndent(tc.args.indent, tc.args.val)
+
+			if diff := cmp.Diff(tc.want.rsp, rsp); diff != "" {
+				t.Errorf("%s\ntoYamlIndent(...): -want rsp, +got rsp:\n%s", tc.reason, diff)
+			}
+
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("%s\ntoYamlIndent(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func Test_fromYamlArray(t *testing.T) {
+	type args struct {
+		val string
+	}
+	type want struct {
+		rsp []any
+		err error
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"UnmarshalYamlArray": {
+			reason: "Should return unmarshalled yaml list",
+			args: args{
+				val: `
+- name: a
+- name: b`,
+			},
+			want: want{
+				rsp: []any{
+					map[string]any{"name": "a"},
+					map[string]any{"name": "b"},
+				},
+			},
+		},
+		"NotAnArray": {
+			reason: "Should return an error if the yaml is not a list",
+			args: args{
+				val: `name: a`,
+			},
+			want: want{
+				err: cmpopts.AnyError,
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			rsp, err := fromYamlArray(tc.args.val)
+
+			if diff := cmp.Diff(tc.want.rsp, rsp); diff != "" {
+				t.Errorf("%s\nfromYamlArray(...): -want rsp, +got rsp:\n%s", tc.reason, diff)
+			}
+
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("%s\nfromYamlArray(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func Test_fromYamlAll(t *testing.T) {
+	type args struct {
+		val string
+	}
+	type want struct {
+		rsp []any
+		err error
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"UnmarshalYamlDocuments": {
+			reason: "Should return all unmarshalled yaml documents and skip empty ones",
+			args: args{
+				val: `---
+kind: A
+---
+---
+kind: B
+`,
+			},
+			want: want{
+				rsp: []any{
+					map[string]any{"kind": "A"},
+					map[string]any{"kind": "B"},
+				},
+			},
+		},
+		"Empty": {
+			reason: "Should return an empty list if there are no documents",
+			args: args{
+				val: ``,
+			},
+			want: want{
+				rsp: []any{},
+			},
+		},
+		"UnmarshalYamlError": {
+			reason: "Should return an error if a document is invalid",
+			args: args{
+				val: `---
+kind: A
+---
+kind: [B
+`,
+			},
+			want: want{
+				err: cmpopts.AnyError,
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			rsp, err := fromYamlAll(tc.args.val)
+
+			if diff := cmp.Diff(tc.want.rsp, rsp); diff != "" {
+				t.Errorf("%s\nfromYamlAll(...): -want rsp, +got rsp:\n%s", tc.reason, diff)
+			}
+
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("%s\nfromYamlAll(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func Test_toJSON(t *testing.T) {
+	type args struct {
+		val any
+	}
+	type want struct {
+		rsp string
+		err error
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"MarshalJSON": {
+			reason: "Should return marshalled json with sorted keys",
+			args: args{
+				val: map[string]any{
+					"b": "x&y",
+					"a": []any{1, "two"},
+				},
+			},
+			want: want{
+				rsp: `{"a":[1,"two"],"b":"x&y"}`,
+			},
+		},
+		"MarshalStruct": {
+			reason: "Should sort the fields of structs",
+			args: args{
+				val: v2.Condition{Type: "Ready", Status: "True", Reason: "Available"},
+			},
+			want: want{
+				rsp: `{"lastTransitionTime":null,"reason":"Available","status":"True","type":"Ready"}`,
+			},
+		},
+		"MarshalJSONError": {
+			reason: "Should return an error if the value cannot be marshalled",
+			args: args{
+				val: map[string]any{"fn": func() {}},
+			},
+			want: want{
+				err: cmpopts.AnyError,
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			rsp, err := toJSON(tc.args.val)
+
+			if diff := cmp.Diff(tc.want.rsp, rsp); diff != "" {
+				t.Errorf("%s\ntoJSON(...): -want rsp, +got rsp:\n%s", tc.reason, diff)
+			}
+
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("%s\ntoJSON(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func Test_fromJSON(t *testing.T) {
+	type args struct {
+		val string
+	}
+	type want struct {
+		rsp any
+		err error
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"UnmarshalJSON": {
+			reason: "Should return unmarshalled json with integers as int64",
+			args: args{
+				val: `{"replicas": 3, "ratio": 0.5, "tags": ["a"]}`,
+			},
+			want: want{
+				rsp: map[string]any{
+					"replicas": int64(3),
+					"ratio":    0.5,
+					"tags":     []any{"a"},
+				},
+			},
+		},
+		"UnmarshalJSONList": {
+			reason: "Should return unmarshalled json lists",
+			args: args{
+				val: `["a", "b"]`,
+			},
+			want: want{
+				rsp: []any{"a", "b"},
+			},
+		},
+		"UnmarshalJSONError": {
+			reason: "Should return an error if the json is invalid",
+			args: args{
+				val: `{"replicas": }`,
+			},
+			want: want{
+				err: cmpopts.AnyError,
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			rsp, err := fromJSON(tc.args.val)
+
+			if diff := cmp.Diff(tc.want.rsp, rsp); diff != "" {
+				t.Errorf("%s\nfromJSON(...): -want rsp, +got rsp:\n%s", tc.reason, diff)
+			}
+
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("%s\nfromJSON(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func Test_getResourceCondition(t *testing.T) {
+	type args struct {
+		ct  string
+		res map[string]any
+	}
+
+	type want struct {
+		rsp v2.Condition
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"GetCondition": {
+			reason: "Should return condition",
+			args: args{
+				ct: "Ready",
+				res: map[string]any{
+					"resource": map[string]any{
+						"status": map[string]any{
+							"conditions": []any{
+								map[string]any{
+									"type":   "Ready",
+									"status": "True",
+								},
+							},
+						},
+					},
+				},
+			},
+			want: want{
+				rsp: v2.Condition{
+					Type:   "Ready",
+					Status: "True",
+				},
+			},
+		},
+		"GetConditionObservedResource": {
+			reason: "Should return condition, even if not wrapped in 'resource'",
+			args: args{
+				ct: "Ready",
+				res: map[string]any{
+					"status": map[string]any{
+						"conditions": []any{
+							map[string]any{
+								"type":   "Ready",
+								"status": "True",
+							},
+						},
+					},
+				},
+			},
+			want: want{
+				rsp: v2.Condition{
+					Type:   "Ready",
+					Status: "True",
+				},
+			},
+		},
+		"GetConditionUnknown": {
+			reason: "Should return an Unknown status",
+			args: args{
+				ct: "Ready",
+				res: map[string]any{
+					"resource": map[string]any{},
+				},
+			},
+			want: want{
+				rsp: v2.Condition{
+					Type:   "Ready",
+					Status: "Unknown",
+				},
+			},
+		},
+		"GetConditionNotFound": {
+			reason: "Should return an Unknown condition when not found",
+			args: args{
+				ct: "Ready",
+				res: map[string]any{
+					"resource": map[string]any{
+						"status": map[string]any{
+							"conditions": []any{
+								map[string]any{
+									"type":   "NotReady",
+									"status": "True",
+								},
+							},
+						},
+					},
+				},
+			},
+			want: want{
+				rsp: v2.Condition{
+					Type:   "Ready",
+					Status: "Unknown",
+				},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			rsp := getResourceCondition(tc.args.ct, tc.args.res)
+
+			if diff := cmp.Diff(tc.want.rsp, rsp, protocmp.Transform()); diff != "" {
+				t.Errorf("%s\ngetResourceCondition(...): -want rsp, +got rsp:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func Test_setResourceNameAnnotation(t *testing.T) {
+	type args struct {
+		name string
+	}
+	type want struct {
+		rsp string
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"SetAnnotationWithGivenName": {
+			reason: "Should return composition resource name annotation with given name",
+			args: args{
+				name: "test",
+			},
+			want: want{
+				rsp: "gotemplating.fn.crossplane.io/composition-resource-name: test",
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			rsp := setResourceNameAnnotation(tc.args.name)
+
+			if diff := cmp.Diff(tc.want.rsp, rsp, protocmp.Transform()); diff != "" {
+				t.Errorf("%s\nsetResourceNameAnnotation(...): -want rsp, +got rsp:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func Test_include(t *testing.T) {
+	type args struct {
+		val string
+	}
+	type want struct {
+		rsp string
+		err error
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"ExecTemplate": {
+			reason: "Should return the executed template",
+			args: args{
+				val: `
+{{- define "test-template" -}}
+value: {{.}}
+{{- end }}
+{{- $var:= include "test-template" "val" -}}
+Must capture output: {{$var}}`,
+			},
+			want: want{
+				rsp: `Must capture output: value: val`,
+			},
+		},
+		"TemplateErrorCtxNotSet": {
+			reason: "Should return error if ctx not set",
+			args: args{
+				val: `
+{{- define "test-template" -}}
+value: {{.}}
+{{- end }}
+{{- $var:= include "test-template" -}}
+Must capture output: {{$var}}
+`,
+			},
+			want: want{
+				err: cmpopts.AnyError,
+			},
+		},
+		"TemplateErrorTemplateNameNotSet": {
+			reason: "Should return error if template name not set",
+			args: args{
+				val: `
+{{- define "test-template" -}}
+value: {{.}}
+{{- end }}
+{{- $var:= include -}}
+Must capture output: {{$var}}
+`,
+			},
+			want: want{
+				err: cmpopts.AnyError,
+			},
+		},
+	}
+
+	tpl := template.New("")
+	tpl.Funcs(template.FuncMap{
+		"include": initInclude(tpl),
+	})
+
+	for name, tc := range cases {
+		_tpl := template.Must(tpl.Parse(tc.args.val))
+		t.Run(name, func(t *testing.T) {
+			rsp := &bytes.Buffer{}
+			err := _tpl.Execute(rsp, nil)
+			if diff := cmp.Diff(tc.want.rsp, rsp.String(), protocmp.Transform()); diff != "" {
+				t.Errorf("%s\nfromYaml(...): -want rsp, +got rsp:\n%s", tc.reason, diff)
+			}
+
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("%s\nfromYaml(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func Test_tpl(t *testing.T) {
+	type args struct {
+		delims *v1beta1.Delims
+		val    string
+		data   any
+	}
+	type want struct {
+		rsp string
+		err error
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"RenderString": {
+			reason: "Should render the string as a template against the data",
+			args: args{
+				val:  `{{ tpl .pattern . }}`,
+				data: map[string]any{"pattern": `{{ .tenant }}-{{ .env | upper }}`, "tenant": "acme", "env": "prod"},
+			},
+			want: want{
+				rsp: `acme-PROD`,
+			},
+		},
+		"SharedTemplates": {
+			reason: "Should be able to use templates defined in the parent template",
+			args: args{
+				val: `
+{{- define "prefix" -}}
+{{ .tenant }}
+{{- end -}}
+{{ tpl .pattern . }}`,
+				data: map[string]any{"pattern": `{{ template "prefix" . }}-db`, "tenant": "acme"},
+			},
+			want: want{
+				rsp: `acme-db`,
+			},
+		},
+		"SharedDelims": {
+			reason: "Should use the delimiters of the parent template",
+			args: args{
+				delims: &v1beta1.Delims{Left: ptr.To("[["), Right: ptr.To("]]")},
+				val:    `[[ tpl .pattern . ]]`,
+				data:   map[string]any{"pattern": `[[ .tenant ]]-{{ literal }}`, "tenant": "acme"},
+			},
+			want: want{
+				rsp: `acme-{{ literal }}`,
+			},
+		},
+		"InvalidTemplate": {
+			reason: "Should return an error if the string cannot be parsed",
+			args: args{
+				val:  `{{ tpl .pattern . }}`,
+				data: map[string]any{"pattern": `{{ .tenant`},
+			},
+			want: want{
+				err: cmpopts.AnyError,
+			},
+		},
+		"Recursion": {
+			reason: "Should return an error if the string renders itself recursively",
+			args: args{
+				val:  `{{ tpl .pattern . }}`,
+				data: map[string]any{"pattern": `{{ tpl .pattern . }}`},
+			},
+			want: want{
+				err: cmpopts.AnyError,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			tpl := template.Must(GetNewTemplateWithFunctionMaps(tc.args.delims).Parse(tc.args.val))
+			rsp := &bytes.Buffer{}
+			err := tpl.Execute(rsp, tc.args.data)
+			if tc.want.err != nil {
+				rsp.Reset()
+			}
+			if diff := cmp.Diff(tc.want.rsp, rsp.String()); diff != "" {
+				t.Errorf("%s\ntpl(...): -want rsp, +got rsp:\n%s", tc.reason, diff)
+			}
+
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("%s\ntpl(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func Test_getComposedResource(t *testing.T) {
+	type args struct {
+		req  map[string]any
+		name string
+	}
+
+	type want struct {
+		rsp map[string]any
+	}
+
+	completeResource := map[string]any{
+		"apiVersion": "dbforpostgresql.azure.upbound.io/v1beta1",
+		"kind":       "FlexibleServer",
+		"spec": map[string]any{
+			"forProvider": map[string]any{
+				"storageMb": "32768",
+			},
+		},
+		"status": map[string]any{
+			"atProvider": map[string]any{
+				"id": "abcdef",
+			},
+		},
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"RetrieveCompleteResource": {
+			reason: "Should successfully retrieve the complete resource",
+			args: args{
+				req: map[string]any{
+					"observed": map[string]any{
+						"resources": map[string]any{
+							"flexserver": map[string]any{
+								"resource": completeResource,
+							},
+						},
+					},
+				},
+				name: "flexserver",
+			},
+			want: want{rsp: completeResource},
+		},
+		"RetrieveCompleteResourceWithDots": {
+			reason: "Should successfully retrieve the complete resource when identifier contains dots",
+			args: args{
+				req: map[string]any{
+					"observed": map[string]any{
+						"resources": map[string]any{
+							"flex.server": map[string]any{
+								"resource": completeResource,
+							},
+						},
+					},
+				},
+				name: "flex.server",
+			},
+			want: want{rsp: completeResource},
+		},
+		"ResourceNotFound": {
+			reason: "Should return nil if the resource is not found",
+			args: args{
+				req: map[string]any{
+					"observed": map[string]any{
+						"resources": map[string]any{},
+					},
+				},
+				name: "missingResource",
+			},
+			want: want{rsp: nil},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := getComposedResource(tc.args.req, tc.args.name)
+			if diff := cmp.Diff(tc.want.rsp, got); diff != "" {
+				t.Errorf("%s\ngetComposedResource(...): -want rsp, +got rsp:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func Test_getCompositeResource(t *testing.T) {
+	type args struct {
+		req map[string]any
+	}
+
+	type want struct {
+		rsp map[string]any
+	}
+
+	compositeResource := map[string]any{
+		"apiVersion": "example.crossplane.io/v1beta1",
+		"kind":       "XR",
+		"metadata": map[string]any{
+			"name": "example",
+		},
+		"spec": map[string]any{
+			"key": "value",
+		},
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"RetrieveCompositeResource": {
+			reason: "Should successfully retrieve the composite resource",
+			args: args{
+				req: map[string]any{
+					"observed": map[string]any{
+						"composite": map[string]any{
+							"resource": compositeResource,
+						},
+					},
+				},
+			},
+			want: want{rsp: compositeResource},
+		},
+		"ResourceNotFound": {
+			reason: "Should return nil if the composite resource is not found",
+			args: args{
+				req: map[string]any{
+					"observed": map[string]any{
+						"composite": map[string]any{},
+					},
+				},
+			},
+			want: want{rsp: nil},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := getCompositeResource(tc.args.req)
+			if diff := cmp.Diff(tc.want.rsp, got); diff != "" {
+				t.Errorf("%s\ngetCompositeResource(...): -want rsp, +got rsp:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func Test_getDesiredComposedResource(t *testing.T) {
+	type args struct {
+		req  map[string]any
+		name string
+	}
+
+	type want struct {
+		rsp map[string]any
+	}
+
+	bucket := map[string]any{
+		"apiVersion": "s3.aws.upbound.io/v1beta1",
+		"kind":       "Bucket",
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"RetrieveDesiredResource": {
+			reason: "Should retrieve the desired resource",
+			args: args{
+				req: map[string]any{
+					"observed": map[string]any{
+						"resources": map[string]any{
+							"bucket": map[string]any{"resource": map[string]any{"kind": "Observed"}},
+						},
+					},
+					"desired": map[string]any{
+						"resources": map[string]any{
+							"bucket": map[string]any{"resource": bucket},
+						},
+					},
+				},
+				name: "bucket",
+			},
+			want: want{rsp: bucket},
+		},
+		"ResourceNotFound": {
+			reason: "Should return nil if the resource is not desired",
+			args: args{
+				req: map[string]any{
+					"desired": map[string]any{
+						"resources": map[string]any{
+							"bucket": map[string]any{"resource": bucket},
+						},
+					},
+				},
+				name: "other",
+			},
+			want: want{rsp: nil},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := getDesiredComposedResource(tc.args.req, tc.args.name)
+			if diff := cmp.Diff(tc.want.rsp, got); diff != "" {
+				t.Errorf("%s\ngetDesiredComposedResource(...): -want rsp, +got rsp:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func Test_getDesiredCompositeResource(t *testing.T) {
+	type args struct {
+		req map[string]any
+	}
+
+	type want struct {
+		rsp map[string]any
+	}
+
+	xr := map[string]any{
+		"apiVersion": "example.crossplane.io/v1",
+		"kind":       "XR",
+		"status":     map[string]any{"ready": true},
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"RetrieveDesiredComposite": {
+			reason: "Should retrieve the desired composite resource",
+			args: args{
+				req: map[string]any{
+					"desired": map[string]any{
+						"composite": map[string]any{"resource": xr},
+					},
+				},
+			},
+			want: want{rsp: xr},
+		},
+		"NoDesiredComposite": {
+			reason: "Should return nil if there is no desired composite resource",
+			args: args{
+				req: map[string]any{},
+			},
+			want: want{rsp: nil},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := getDesiredCompositeResource(tc.args.req)
+			if diff := cmp.Diff(tc.want.rsp, got); diff != "" {
+				t.Errorf("%s\ngetDesiredCompositeResource(...): -want rsp, +got rsp:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func Test_listComposedResources(t *testing.T) {
+	type args struct {
+		req    map[string]any
+		state  string
+		filter []map[string]any
+	}
+
+	type want struct {
+		rsp map[string]any
+		err error
+	}
+
+	bucket := map[string]any{
+		"apiVersion": "s3.aws.upbound.io/v1beta1",
+		"kind":       "Bucket",
+		"metadata": map[string]any{
+			"labels": map[string]any{"tier": "storage", "env": "prod"},
+		},
+	}
+	oldBucket := map[string]any{
+		"apiVersion": "s3.aws.upbound.io/v1alpha1",
+		"kind":       "Bucket",
+	}
+	role := map[string]any{
+		"apiVersion": "iam.aws.upbound.io/v1beta1",
+		"kind":       "Role",
+		"metadata": map[string]any{
+			"labels": map[string]any{"env": "dev"},
+		},
+	}
+	req := map[string]any{
+		"observed": map[string]any{
+			"resources": map[string]any{
+				"bucket": map[string]any{"resource": bucket},
+			},
+		},
+		"desired": map[string]any{
+			"resources": map[string]any{
+				"bucket":     map[string]any{"resource": bucket},
+				"old-bucket": map[string]any{"resource": oldBucket},
+				"role":       map[string]any{"resource": role},
+			},
+		},
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"AllObserved": {
+			reason: "Should return all observed resources without a filter",
+			args:   args{req: req, state: "observed"},
+			want:   want{rsp: map[string]any{"bucket": bucket}},
+		},
+		"ByKind": {
+			reason: "Should return desired resources of the supplied kind",
+			args:   args{req: req, state: "desired", filter: []map[string]any{{"kind": "Bucket"}}},
+			want:   want{rsp: map[string]any{"bucket": bucket, "old-bucket": oldBucket}},
+		},
+		"ByAPIVersionAndKind": {
+			reason: "Should return desired resources of the supplied apiVersion and kind",
+			args:   args{req: req, state: "desired", filter: []map[string]any{{"apiVersion": "s3.aws.upbound.io/v1beta1", "kind": "Bucket"}}},
+			want:   want{rsp: map[string]any{"bucket": bucket}},
+		},
+		"ByLabelSelectorString": {
+			reason: "Should return desired resources matching a label selector",
+			args:   args{req: req, state: "desired", filter: []map[string]any{{"labelSelector": "env in (prod,dev),tier!=storage"}}},
+			want:   want{rsp: map[string]any{"role": role}},
+		},
+		"ByLabelSelectorMap": {
+			reason: "Should return desired resources with all supplied labels",
+			args:   args{req: req, state: "desired", filter: []map[string]any{{"labelSelector": map[string]any{"env": "prod"}}}},
+			want:   want{rsp: map[string]any{"bucket": bucket}},
+		},
+		"NoResources": {
+			reason: "Should return an empty map if there are no resources",
+			args:   args{req: map[string]any{}, state: "desired"},
+			want:   want{rsp: map[string]any{}},
+		},
+		"InvalidState": {
+			reason: "Should return an error for an unknown state",
+			args:   args{req: req, state: "actual"},
+			want:   want{err: cmpopts.AnyError},
+		},
+		"InvalidFilterField": {
+			reason: "Should return an error for an unknown filter field",
+			args:   args{req: req, state: "desired", filter: []map[string]any{{"name": "bucket"}}},
+			want:   want{err: cmpopts.AnyError},
+		},
+		"InvalidLabelSelector": {
+			reason: "Should return an error for an invalid label selector",
+			args:   args{req: req, state: "desired", filter: []map[string]any{{"labelSelector": "env in (prod"}}},
+			want:   want{err: cmpopts.AnyError},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := listComposedResources(tc.args.req, tc.args.state, tc.args.filter...)
+			if diff := cmp.Diff(tc.want.rsp, got); diff != "" {
+				t.Errorf("%s\nlistComposedResources(...): -want rsp, +got rsp:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("%s\nlistComposedResources(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func Test_getComposedConnectionDetails(t *testing.T) {
+	type args struct {
+		req  map[string]any
+		name string
+	}
+
+	type want struct {
+		rsp map[string]any
+	}
+
+	connectionDetails := map[string]any{
+		"username": "dXNlcg==",
+		"password": "cGFzcw==",
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"RetrieveConnectionDetails": {
+			reason: "Should successfully retrieve connection details for a composed resource",
+			args: args{
+				req: map[string]any{
+					"observed": map[string]any{
+						"resources": map[string]any{
+							"my-resource": map[string]any{
+								"connectionDetails": connectionDetails,
+							},
+						},
+					},
+				},
+				name: "my-resource",
+			},
+			want: want{rsp: connectionDetails},
+		},
+		"RetrieveConnectionDetailsWithDots": {
+			reason: "Should successfully retrieve connection details when identifier contains dots",
+			args: args{
+				req: map[string]any{
+					"observed": map[string]any{
+						"resources": map[string]any{
+							"my.resource": map[string]any{
+								"connectionDetails": connectionDetails,
+							},
+						},
+					},
+				},
+				name: "my.resource",
+			},
+			want: want{rsp: connectionDetails},
+		},
+		"MissingConnectionDetails": {
+			reason: "Should return nil if the resource has no connectionDetails",
+			args: args{
+				req: map[string]any{
+					"observed": map[string]any{
+						"resources": map[string]any{
+							"my-resource": map[string]any{},
+						},
+					},
+				},
+				name: "my-resource",
+			},
+			want: want{rsp: nil},
+		},
+		"ResourceNotFound": {
+			reason: "Should return nil if the resource is not found",
+			args: args{
+				req: map[string]any{
+					"observed": map[string]any{
+						"resources": map[string]any{},
+					},
+				},
+				name: "my-resource",
+			},
+			want: want{rsp: nil},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := getComposedConnectionDetails(tc.args.req, tc.args.name)
+			if diff := cmp.Diff(tc.want.rsp, got); diff != "" {
+				t.Errorf("%s\ngetComposedConnectionDetails(...): -want rsp, +got rsp:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func Test_getExtraResources(t *testing.T) {
+	type args struct {
+		req  map[string]any
+		name string
+	}
+
+	type want struct {
+		rsp []any
+	}
+
+	completeResource := map[string]any{
+		"apiVersion": "dbforpostgresql.azure.upbound.io/v1beta1",
+		"kind":       "FlexibleServer",
+		"spec": map[string]any{
+			"forProvider": map[string]any{
+				"storageMb": "32768",
+			},
+		},
+		"status": map[string]any{
+			"atProvider": map[string]any{
+				"id": "abcdef",
+			},
+		},
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"RetrieveCompleteResource": {
+			reason: "Should successfully retrieve the complete resource",
+			args: args{
+				req: map[string]any{
+					"requiredResources": map[string]any{
+						"flexserver": map[string]any{
+							"items": []any{
+								completeResource,
+							},
+						},
+					},
+				},
+				name: "flexserver",
+			},
+			want: want{
+				rsp: []any{
+					completeResource,
+				},
+			},
+		},
+		"RetrieveExtraResource": {
+			reason: "Should successfully retrieve the resource from extraResources",
+			args: args{
+				req: map[string]any{
+					"extraResources": map[string]any{
+						"flexserver": map[string]any{
+							"items": []any{
+								completeResource,
+							},
+						},
+					},
+				},
+				name: "flexserver",
+			},
+			want: want{
+				rsp: []any{
+					completeResource,
+				},
+			},
+		},
+		"ResourceNotFound": {
+			reason: "Should return empty list if no extra resources are found",
+			args: args{
+				req: map[string]any{
+					"requiredResources": map[string]any{
+						"flexserver": map[string]any{
+							"items": []any{},
+						},
+					},
+				},
+				name: "flexserver",
+			},
+			want: want{rsp: []any{}},
+		},
+		"NoExtraResources": {
+			reason: "Should return nil if no extra resources are available",
+			args: args{
+				req:  map[string]any{},
+				name: "flexserver",
+			},
+			want: want{rsp: nil},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := getExtraResources(tc.args.req, tc.args.name)
+			if diff := cmp.Diff(tc.want.rsp, got); diff != "" {
+				t.Errorf("%s\ngetExtraResources(...): -want rsp, +got rsp:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func extraResourcesRequest() map[string]any {
+	cm := func(name, namespace, region string) map[string]any {
+		r := map[string]any{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata": map[string]any{
+				"name":      name,
+				"namespace": namespace,
+			},
+		}
+		if region != "" {
+			r["metadata"].(map[string]any)["labels"] = map[string]any{"region": region}
+		}
+		return map[string]any{"resource": r}
+	}
+
+	return map[string]any{
+		"requiredResources": map[string]any{
+			"configs": map[string]any{
+				"items": []any{
+					cm("a", "team-a", "eu"),
+					cm("b", "team-a", "us"),
+					cm("c", "team-b", "eu"),
+					cm("d", "team-b", ""),
+				},
+			},
+			"single": map[string]any{
+				"items": []any{
+					cm("a", "team-a", "eu"),
+				},
+			},
+		},
+	}
+}
+
+func Test_getExtraResource(t *testing.T) {
+	type want struct {
+		rsp map[string]any
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		name   string
+		want   want
+	}{
+		"Single": {
+			reason: "Should return the only extra resource",
+			name:   "single",
+			want: want{rsp: map[string]any{
+				"apiVersion": "v1",
+				"kind":       "ConfigMap",
+				"metadata": map[string]any{
+					"name":      "a",
+					"namespace": "team-a",
+					"labels":    map[string]any{"region": "eu"},
+				},
+			}},
+		},
+		"None": {
+			reason: "Should return nil if the requirement has no resources",
+			name:   "missing",
+			want:   want{rsp: nil},
+		},
+		"Multiple": {
+			reason: "Should return an error if the requirement has more than one resource",
+			name:   "configs",
+			want:   want{err: cmpopts.AnyError},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := getExtraResource(extraResourcesRequest(), tc.name)
+			if diff := cmp.Diff(tc.want.rsp, got); diff != "" {
+				t.Errorf("%s\ngetExtraResource(...): -want rsp, +got rsp:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("%s\ngetExtraResource(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func Test_findExtraResource(t *testing.T) {
+	type want struct {
+		name string
+		err  error
+	}
+
+	cases := map[string]struct {
+		reason string
+		filter map[string]any
+		want   want
+	}{
+		"ByName": {
+			reason: "Should find the resource with the supplied name",
+			filter: map[string]any{"name": "b"},
+			want:   want{name: "b"},
+		},
+		"ByNamespaceAndLabel": {
+			reason: "Should find the resource in the namespace with the label",
+			filter: map[string]any{"namespace": "team-b", "labelSelector": map[string]any{"region": "eu"}},
+			want:   want{name: "c"},
+		},
+		"ByLabelSelectorString": {
+			reason: "Should find the resource matching the label selector",
+			filter: map[string]any{"labelSelector": "region,region notin (eu)"},
+			want:   want{name: "b"},
+		},
+		"NotFound": {
+			reason: "Should return nil if no resource matches",
+			filter: map[string]any{"name": "e"},
+			want:   want{},
+		},
+		"Multiple": {
+			reason: "Should return an error if more than one resource matches",
+			filter: map[string]any{"namespace": "team-a"},
+			want:   want{err: cmpopts.AnyError},
+		},
+		"InvalidField": {
+			reason: "Should return an error for an unknown filter field",
+			filter: map[string]any{"kind": "ConfigMap"},
+			want:   want{err: cmpopts.AnyError},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := findExtraResource(extraResourcesRequest(), "configs", tc.filter)
+			n, _ := getFieldOr(got, "metadata.name", "")
+			if diff := cmp.Diff(tc.want.name, n); diff != "" {
+				t.Errorf("%s\nfindExtraResource(...): -want name, +got name:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("%s\nfindExtraResource(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func Test_extraResourcesByLabel(t *testing.T) {
+	got := extraResourcesByLabel(extraResourcesRequest(), "configs", "region")
+
+	names := make(map[string][]string)
+	for region, group := range got {
+		for _, r := range group.([]any) {
+			n, _ := getField(r, "metadata.name")
+			names[region] = append(names[region], n.(string))
+		}
+	}
+
+	want := map[string][]string{
+		"eu": {"a", "c"},
+		"us": {"b"},
+	}
+	if diff := cmp.Diff(want, names); diff != "" {
+		t.Errorf("extraResourcesByLabel(...): -want, +got:\n%s", diff)
+	}
+}
+
+func Test_getExtraResourcesFromContext(t *testing.T) {
+	type args struct {
+		req  map[string]any
+		name string
+	}
+
+	type want struct {
+		rsp []any
+	}
+
+	completeResource := map[string]any{
+		"apiVersion": "dbforpostgresql.azure.upbound.io/v1beta1",
+		"kind":       "FlexibleServer",
+		"spec": map[string]any{
+			"forProvider": map[string]any{
+				"storageMb": "32768",
+			},
+		},
+		"status": map[string]any{
+			"atProvider": map[string]any{
+				"id": "abcdef",
+			},
+		},
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"RetrieveCompleteResource": {
+			reason: "Should successfully retrieve the complete resource",
+			args: args{
+				req: map[string]any{
+					"context": map[string]any{
+						"apiextensions.crossplane.io/extra-resources": map[string]any{
+							"flexserver": map[string]any{
+								"items": []any{
+									completeResource,
+								},
+							},
+						},
+					},
+				},
+				name: "flexserver",
+			},
+			want: want{
+				rsp: []any{
+					completeResource,
+				},
+			},
+		},
+		"ResourceNotFound": {
+			reason: "Should return empty list if no extra resources are found",
+			args: args{
+				req: map[string]any{
+					"context": map[string]any{
+						"apiextensions.crossplane.io/extra-resources": map[string]any{
+							"flexserver": map[string]any{
+								"items": []any{},
+							},
+						},
+					},
+				},
+				name: "flexserver",
+			},
+			want: want{rsp: []any{}},
+		},
+		"NoExtraResources": {
+			reason: "Should return nil if no extra resources are available",
+			args: args{
+				req:  map[string]any{},
+				name: "flexserver",
+			},
+			want: want{rsp: nil},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := getExtraResourcesFromContext(tc.args.req, tc.args.name)
+			if diff := cmp.Diff(tc.want.rsp, got); diff != "" {
+				t.Errorf("%s\ngetExtraResourcesFromContext(...): -want rsp, +got rsp:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func Test_getCredentialData(t *testing.T) {
+	type args struct {
+		req *fnv1.RunFunctionRequest
+	}
+
+	type want struct {
+		data map[string][]byte
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"RetrieveFunctionCredential": {
+			reason: "Should successfully retrieve the function credential",
+			args: args{
+				req: &fnv1.RunFunctionRequest{
+					Credentials: map[string]*fnv1.Credentials{
+						"foo-creds": {
+							Source: &fnv1.Credentials_CredentialData{
+								CredentialData: &fnv1.CredentialData{
+									Data: map[string][]byte{
+										"password": []byte("secret"),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			want: want{
+				data: map[string][]byte{
+					"password": []byte("secret"),
+				},
+			},
+		},
+		"FunctionCredentialNotFound": {
+			reason: "Should return nil if the function credential is not found",
+			args: args{
+				req: &fnv1.RunFunctionRequest{
+					Credentials: map[string]*fnv1.Credentials{},
+				},
+			},
+			want: want{data: nil},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			req, _ := convertToMap(tc.args.req)
+			got, err := getCredentialData(req, "foo-creds")
+			if err != nil {
+				t.Fatalf("getCredentialData(...): %v", err)
+			}
+			if diff := cmp.Diff(tc.want.data, got); diff != "" {
+				t.Errorf("%s\ngetCredentialData(...): -want data, +got data:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func Test_compositeIdentity(t *testing.T) {
+	type args struct {
+		req map[string]any
+	}
+
+	type want struct {
+		rsp string
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"UID": {
+			reason: "Should return the UID of the observed composite resource",
+			args: args{
+				req: map[string]any{
+					"observed": map[string]any{
+						"composite": map[string]any{
+							"resource": map[string]any{
+								"kind": "XR",
+								"metadata": map[string]any{
+									"name": "example",
+									"uid":  "6a6c4b1e-9f0d-4f55-a4d2-3c2f0f1e7b9a",
+								},
+							},
+						},
+					},
+				},
+			},
+			want: want{rsp: "6a6c4b1e-9f0d-4f55-a4d2-3c2f0f1e7b9a"},
+		},
+		"NoUID": {
+			reason: "Should fall back to kind, namespace and name if the UID is not set",
+			args: args{
+				req: map[string]any{
+					"observed": map[string]any{
+						"composite": map[string]any{
+							"resource": map[string]any{
+								"kind": "XR",
+								"metadata": map[string]any{
+									"name":      "example",
+									"namespace": "default",
+								},
+							},
+						},
+					},
+				},
+			},
+			want: want{rsp: "XR/default/example"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := compositeIdentity(tc.args.req)
+			if diff := cmp.Diff(tc.want.rsp, got); diff != "" {
+				t.Errorf("%s\ncompositeIdentity(...): -want rsp, +got rsp:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func Test_stableFunctions(t *testing.T) {
+	req := map[string]any{
+		"observed": map[string]any{
+			"composite": map[string]any{
+				"resource": map[string]any{
+					"metadata": map[string]any{
+						"uid": "6a6c4b1e-9f0d-4f55-a4d2-3c2f0f1e7b9a",
+					},
+				},
+			},
+		},
+	}
+
+	type want struct {
+		rsp string
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		tmpl   string
+		want   want
+	}{
+		"StableChoice": {
+			reason: "Should return the same choice for the same composite and salt",
+			tmpl:   `{{ stableChoice . "az" "a" "b" "c" }}`,
+			want:   want{rsp: "c"},
+		},
+		"StableChoiceDifferentSalt": {
+			reason: "Should seed the choice with the salt",
+			tmpl:   `{{ stableChoice . "zone" "a" "b" "c" }}`,
+			want:   want{rsp: "b"},
+		},
+		"StableChoiceEmpty": {
+			reason: "Should return an error if there are no choices",
+			tmpl:   `{{ stableChoice . "az" }}`,
+			want:   want{err: cmpopts.AnyError},
+		},
+		"StableRandAlphaNum": {
+			reason: "Should return the same string for the same composite and salt",
+			tmpl:   `{{ stableRandAlphaNum . "suffix" 8 }}`,
+			want:   want{rsp: "cIwdKAqO"},
+		},
+		"StableRandAlphaNumNegativeLength": {
+			reason: "Should return an error if the length is less than 1",
+			tmpl:   `{{ stableRandAlphaNum . "suffix" -1 }}`,
+			want:   want{err: cmpopts.AnyError},
+		},
+		"StableShuffle": {
+			reason: "Should return the same order for the same composite and salt",
+			tmpl:   `{{ stableShuffle . "order" (list "a" "b" "c" "d") | join "," }}`,
+			want:   want{rsp: "b,d,c,a"},
+		},
+		"StableShuffleNotAList": {
+			reason: "Should return an error if the argument is not a list",
+			tmpl:   `{{ stableShuffle . "order" "abcd" }}`,
+			want:   want{err: cmpopts.AnyError},
+		},
+		"StableInt": {
+			reason: "Should return the same integer for the same composite and salt",
+			tmpl:   `{{ stableInt . "port" 30000 32768 }}`,
+			want:   want{rsp: "30886"},
+		},
+		"StableIntInvalidRange": {
+			reason: "Should return an error if max is not greater than min",
+			tmpl:   `{{ stableInt . "port" 10 10 }}`,
+			want:   want{err: cmpopts.AnyError},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			tpl := template.Must(GetNewTemplateWithFunctionMaps(nil).Parse(tc.tmpl))
+
+			for range 2 {
+				rsp := &bytes.Buffer{}
+				err := tpl.Execute(rsp, req)
+				if tc.want.err != nil {
+					rsp.Reset()
+				}
+
+				if diff := cmp.Diff(tc.want.rsp, rsp.String()); diff != "" {
+					t.Errorf("%s\nExecute(...): -want rsp, +got rsp:\n%s", tc.reason, diff)
+				}
+
+				if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+					t.Errorf("%s\nExecute(...): -want err, +got err:\n%s", tc.reason, diff)
+				}
+			}
+		})
+	}
+}
+
+func Test_getField(t *testing.T) {
+	obj := map[string]any{
+		"spec": map[string]any{
+			"forProvider": map[string]any{
+				"tags": map[string]any{
+					"env":            "prod",
+					"example.org/id": "1234",
+				},
+				"subnets": []any{"a", "b"},
+			},
+		},
+	}
+
+	type args struct {
+		obj  any
+		path string
+	}
+	type want struct {
+		rsp any
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"MapKey": {
+			reason: "Should return the value of a map key",
+			args:   args{obj: obj, path: "spec.forProvider.tags[env]"},
+			want:   want{rsp: "prod"},
+		},
+		"MapKeyWithDots": {
+			reason: "Should return the value of a map key that contains dots",
+			args:   args{obj: obj, path: "spec.forProvider.tags[example.org/id]"},
+			want:   want{rsp: "1234"},
+		},
+		"ListIndex": {
+			reason: "Should return the value of a list index",
+			args:   args{obj: obj, path: "spec.forProvider.subnets[1]"},
+			want:   want{rsp: "b"},
+		},
+		"NotFound": {
+			reason: "Should return an error if the field does not exist",
+			args:   args{obj: obj, path: "spec.forProvider.subnets[2]"},
+			want:   want{err: cmpopts.AnyError},
+		},
+		"Struct": {
+			reason: "Should convert objects that are not maps",
+			args:   args{obj: v2.Condition{Type: "Ready", Status: "True"}, path: "status"},
+			want:   want{rsp: "True"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			rsp, err := getField(tc.args.obj, tc.args.path)
+			if diff := cmp.Diff(tc.want.rsp, rsp); diff != "" {
+				t.Errorf("%s\ngetField(...): -want rsp, +got rsp:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("%s\ngetField(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func Test_getFieldOr(t *testing.T) {
+	obj := map[string]any{
+		"metadata": map[string]any{
+			"name": "example",
+		},
+	}
+
+	type args struct {
+		obj  any
+		path string
+		def  any
+	}
+	type want struct {
+		rsp any
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Found": {
+			reason: "Should return the value if the field exists",
+			args:   args{obj: obj, path: "metadata.name", def: "default"},
+			want:   want{rsp: "example"},
+		},
+		"NotFound": {
+			reason: "Should return the default if the field does not exist",
+			args:   args{obj: obj, path: "metadata.labels[team]", def: "default"},
+			want:   want{rsp: "default"},
+		},
+		"NilObject": {
+			reason: "Should return the default if the object is nil",
+			args:   args{obj: nil, path: "metadata.name", def: "default"},
+			want:   want{rsp: "default"},
+		},
+		"InvalidPath": {
+			reason: "Should return an error if the path is invalid",
+			args:   args{obj: obj, path: "metadata[name", def: "default"},
+			want:   want{err: cmpopts.AnyError},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			rsp, err := getFieldOr(tc.args.obj, tc.args.path, tc.args.def)
+			if diff := cmp.Diff(tc.want.rsp, rsp); diff != "" {
+				t.Errorf("%s\ngetFieldOr(...): -want rsp, +got rsp:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("%s\ngetFieldOr(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func Test_hasField(t *testing.T) {
+	obj := map[string]any{
+		"status": map[string]any{
+			"atProvider": map[string]any{
+				"arn": "arn:aws:s3:::example",
+			},
+		},
+	}
+
+	type args struct {
+		obj  any
+		path string
+	}
+	type want struct {
+		rsp bool
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Exists": {
+			reason: "Should return true if the field exists",
+			args:   args{obj: obj, path: "status.atProvider.arn"},
+			want:   want{rsp: true},
+		},
+		"DoesNotExist": {
+			reason: "Should return false if the field does not exist",
+			args:   args{obj: obj, path: "status.atProvider.id"},
+			want:   want{rsp: false},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			rsp, err := hasField(tc.args.obj, tc.args.path)
+			if diff := cmp.Diff(tc.want.rsp, rsp); diff != "" {
+				t.Errorf("%s\nhasField(...): -want rsp, +got rsp:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("%s\nhasField(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func Test_GetNewTemplateWithFunctionMaps(t *testing.T) {
+	type want struct {
+		rsp string
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		tmpl   string
+		want   want
+	}{
+		"SprigFromJson": {
+			reason: "Should keep Sprig's fromJson, which returns nothing for invalid JSON",
+			tmpl:   `{{ fromJson "" | empty }}`,
+			want:   want{rsp: `true`},
+		},
+		"SprigToJson": {
+			reason: "Should keep Sprig's toJson, which escapes HTML",
+			tmpl:   `{{ dict "b" "<x>" "a" 1 | toJson }}`,
+			want:   want{rsp: `{"a":1,"b":"\u003cx\u003e"}`},
+		},
+		"FromJsonStrict": {
+			reason: "Should provide fromJsonStrict, which returns errors",
+			tmpl:   `{{ fromJsonStrict "{" }}`,
+			want:   want{err: cmpopts.AnyError},
+		},
+		"ToJsonStrict": {
+			reason: "Should provide toJsonStrict, which does not escape HTML",
+			tmpl:   `{{ dict "b" "<x>" "a" 1 | toJsonStrict }}`,
+			want:   want{rsp: `{"a":1,"b":"<x>"}`},
+		},
+		"SprigFunctions": {
+			reason: "Should provide Sprig functions",
+			tmpl:   `{{ "abc" | upper }}`,
+			want:   want{rsp: `ABC`},
+		},
+		"SprigEnvRemoved": {
+			reason: "Should not provide Sprig's env function",
+			tmpl:   `{{ env "HOME" }}`,
+			want:   want{err: cmpopts.AnyError},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			rsp := &bytes.Buffer{}
+			tpl, err := GetNewTemplateWithFunctionMaps(nil).Parse(tc.tmpl)
+			if err == nil {
+				err = tpl.Execute(rsp, nil)
+			}
+			if tc.want.err != nil {
+				rsp.Reset()
+			}
+
+			if diff := cmp.Diff(tc.want.rsp, rsp.String()); diff != "" {
+				t.Errorf("%s\nExecute(...): -want rsp, +got rsp:\n%s", tc.reason, diff)
+			}
+
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("%s\nExecute(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func Test_toToml(t *testing.T) {
+	type want struct {
+		rsp string
+		err error
+	}
+	cases := map[string]struct {
+		reason string
+		val    any
+		want   want
+	}{
+		"MarshalToml": {
+			reason: "Should return marshalled toml",
+			val: map[string]any{
+				"title": "example",
+				"server": map[string]any{
+					"port":  8080,
+					"hosts": []any{"a", "b"},
+				},
+			},
+			want: want{
+				rsp: `title = 'example'
+
+[server]
+hosts = ['a', 'b']
+port = 8080
+`,
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			rsp, err := toToml(tc.val)
+			if diff := cmp.Diff(tc.want.rsp, rsp); diff != "" {
+				t.Errorf("%s\ntoToml(...): -want rsp, +got rsp:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("%s\ntoToml(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func Test_fromToml(t *testing.T) {
+	type want struct {
+		rsp map[string]any
+		err error
+	}
+	cases := map[string]struct {
+		reason string
+		val    string
+		want   want
+	}{
+		"UnmarshalToml": {
+			reason: "Should return unmarshalled toml",
+			val: `title = "example"
+[server]
+port = 8080
+`,
+			want: want{
+				rsp: map[string]any{
+					"title":  "example",
+					"server": map[string]any{"port": int64(8080)},
+				},
+			},
+		},
+		"UnmarshalTomlError": {
+			reason: "Should return an error if the toml is invalid",
+			val:    `title = `,
+			want:   want{err: cmpopts.AnyError},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			rsp, err := fromToml(tc.val)
+			if diff := cmp.Diff(tc.want.rsp, rsp); diff != "" {
+				t.Errorf("%s\nfromToml(...): -want rsp, +got rsp:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("%s\nfromToml(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func Test_toIni(t *testing.T) {
+	type want struct {
+		rsp string
+		err error
+	}
+	cases := map[string]struct {
+		reason string
+		val    map[string]any
+		want   want
+	}{
+		"MarshalIni": {
+			reason: "Should write top-level keys before sections",
+			val: map[string]any{
+				"name": "example",
+				"database": map[string]any{
+					"host": "db.example.org",
+					"port": 5432,
+				},
+			},
+			want: want{
+				rsp: `name = example
+
+[database]
+host = db.example.org
+port = 5432
+`,
+			},
+		},
+		"NestedSections": {
+			reason: "Should return an error for nested sections",
+			val: map[string]any{
+				"database": map[string]any{
+					"primary": map[string]any{"host": "db.example.org"},
+				},
+			},
+			want: want{err: cmpopts.AnyError},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			rsp, err := toIni(tc.val)
+			if diff := cmp.Diff(tc.want.rsp, rsp); diff != "" {
+				t.Errorf("%s\ntoIni(...): -want rsp, +got rsp:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("%s\ntoIni(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func Test_fromIni(t *testing.T) {
+	type want struct {
+		rsp map[string]any
+		err error
+	}
+	cases := map[string]struct {
+		reason string
+		val    string
+		want   want
+	}{
+		"UnmarshalIni": {
+			reason: "Should return top-level keys and sections",
+			val: `name = example
+[database]
+host = db.example.org
+port = 5432
+`,
+			want: want{
+				rsp: map[string]any{
+					"name": "example",
+					"database": map[string]any{
+						"host": "db.example.org",
+						"port": "5432",
+					},
+				},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			rsp, err := fromIni(tc.val)
+			if diff := cmp.Diff(tc.want.rsp, rsp); diff != "" {
+				t.Errorf("%s\nfromIni(...): -want rsp, +got rsp:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("%s\nfromIni(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func Test_toProperties(t *testing.T) {
+	type want struct {
+		rsp string
+		err error
+	}
+	cases := map[string]struct {
+		reason string
+		val    map[string]any
+		want   want
+	}{
+		"MarshalProperties": {
+			reason: "Should flatten nested maps and lists and escape special characters",
+			val: map[string]any{
+				"spring": map[string]any{
+					"datasource": map[string]any{
+						"url": "jdbc:postgresql://db:5432/app",
+					},
+					"profiles": []any{"prod", "eu"},
+				},
+				"greeting":  " Grüße\n",
+				"key=value": true,
+			},
+			want: want{
+				rsp: `greeting=\ Gr\u00fc\u00dfe\n
+key\=value=true
+spring.datasource.url=jdbc:postgresql://db:5432/app
+spring.profiles[0]=prod
+spring.profiles[1]=eu
+`,
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			rsp, err := toProperties(tc.val)
+			if diff := cmp.Diff(tc.want.rsp, rsp); diff != "" {
+				t.Errorf("%s\ntoProperties(...): -want rsp, +got rsp:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("%s\ntoProperties(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func Test_toHcl(t *testing.T) {
+	type want struct {
+		rsp string
+		err error
+	}
+	cases := map[string]struct {
+		reason string
+		val    map[string]any
+		want   want
+	}{
+		"MarshalHcl": {
+			reason: "Should return HCL attributes",
+			val: map[string]any{
+				"region":    "eu-west-1",
+				"replicas":  int64(3),
+				"enabled":   true,
+				"zones":     []string{"a", "b"},
+				"empty":     []any{},
+				"template":  "${var.name} \"quoted\"",
+				"nothing":   nil,
+				"tags":      map[string]any{"env": "prod", "example.org/team": "platform"},
+				"cidrBlock": "10.0.0.0/16",
+			},
+			want: want{
+				rsp: `cidrBlock = "10.0.0.0/16"
+empty = []
+enabled = true
+nothing = null
+region = "eu-west-1"
+replicas = 3
+tags = {
+  env = "prod"
+  "example.org/team" = "platform"
+}
+template = "$${var.name} \"quoted\""
+zones = [
+  "a",
+  "b",
+]
+`,
+			},
+		},
+		"InvalidAttribute": {
+			reason: "Should return an error if an attribute name is not a valid identifier",
+			val:    map[string]any{"example.org/team": "platform"},
+			want:   want{err: cmpopts.AnyError},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			rsp, err := toHcl(tc.val)
+			if diff := cmp.Diff(tc.want.rsp, rsp); diff != "" {
+				t.Errorf("%s\ntoHcl(...): -want rsp, +got rsp:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("%s\ntoHcl(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package render
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"github.com/crossplane/function-sdk-go/errors"
+)
+
+const (
+	// nameHashLength is the number of hex characters of the hash suffix that
+	// is appended to truncated names.
+	nameHashLength = 8
+
+	maxLabelValueLength          = 63
+	maxS3BucketNameLength        = 63
+	minS3BucketNameLength        = 3
+	maxAzureStorageAccountLength = 24
+	minAzureStorageAccountLength = 3
+)
+
+// k8sName returns a DNS-1123 label built from prefix and name that is at most
+// maxLen characters long. Names that exceed maxLen are truncated and suffixed
+// with a hash of the original input so that they stay unique.
+func k8sName(prefix, name string, maxLen int) (string, error) {
+	in := joinNameParts("-", prefix, name)
+	out := sanitizeName(strings.ToLower(in), isDNSLabelRune, '-')
+
+	return truncateName(in, out, maxLen, "-")
+}
+
+// k8sLabelValue returns a valid Kubernetes label value. Invalid characters are
+// replaced and values longer than 63 characters are truncated and suffixed
+// with a hash of the original value.
+func k8sLabelValue(value string) (string, error) {
+	out := sanitizeName(value, isLabelValueRune, '-')
+	if out == "" {
+		// The empty string is a valid label value.
+		return "", nil
+	}
+
+	return truncateName(value, out, maxLabelValueLength, "-")
+}
+
+// s3BucketName returns a valid S3 bucket name built from prefix and name.
+func s3BucketName(prefix, name string) (string, error) {
+	in := joinNameParts("-", prefix, name)
+	out := sanitizeName(strings.ToLower(in), isDNSLabelRune, '-')
+
+	out, err := truncateName(in, out, maxS3BucketNameLength, "-")
+	if err != nil {
+		return "", err
+	}
+	if len(out) < minS3BucketNameLength {
+		return "", errors.Errorf("S3 bucket name %q must be at least %d characters long", out, minS3BucketNameLength)
+	}
+
+	return out, nil
+}
+
+// azureStorageAccountName returns a valid Azure storage account name built
+// from prefix and name. Storage account names may only contain lowercase
+// letters and numbers.
+func azureStorageAccountName(prefix, name string) (string, error) {
+	in := joinNameParts("", prefix, name)
+	out := strings.Map(func(r rune) rune {
+		if isLowerAlphaNum(r) {
+			return r
+		}
+		return -1
+	}, strings.ToLower(in))
+
+	out, err := truncateName(in, out, maxAzureStorageAccountLength, "")
+	if err != nil {
+		return "", err
+	}
+	if len(out) < minAzureStorageAccountLength {
+		return "", errors.Errorf("Azure storage account name %q must be at least %d characters long", out, minAzureStorageAccountLength)
+	}
+
+	return out, nil
+}
+
+// joinNameParts joins the non-empty parts with sep.
+func joinNameParts(sep string, parts ...string) string {
+	nonEmpty := make([]string, 0, len(parts))
+	for _, p := range parts {
+		if p != "" {
+			nonEmpty = append(nonEmpty, p)
+		}
+	}
+
+	return strings.Join(nonEmpty, sep)
+}
+
+// sanitizeName replaces every rune of s that is not allowed with sep, collapses
+// consecutive separators and trims leading and trailing separators as well as
+// any other non-alphanumeric characters.
+func sanitizeName(s string, allowed func(r rune) bool, sep rune) string {
+	var b strings.Builder
+	last := rune(0)
+	for _, r := range s {
+		if !allowed(r) {
+			r = sep
+		}
+		if r == sep && last == sep {
+			continue
+		}
+		b.WriteRune(r)
+		last = r
+	}
+
+	return trimNonAlphaNum(b.String())
+}
+
+// truncateName truncates name to maxLen characters. Names that are too long
+// are suffixed with sep and a hash of the original input.
+func truncateName(original, name string, maxLen int, sep string) (string, error) {
+	if name == "" {
+		return "", errors.Errorf("cannot generate a name from %q", original)
+	}
+	if len(name) <= maxLen {
+		return name, nil
+	}
+	if maxLen < nameHashLength+len(sep)+1 {
+		return "", errors.Errorf("cannot truncate %q to %d characters: must allow at least %d characters", name, maxLen, nameHashLength+len(sep)+1)
+	}
+
+	sum := sha256.Sum256([]byte(original))
+	hash := hex.EncodeToString(sum[:])[:nameHashLength]
+	head := trimNonAlphaNum(name[:maxLen-nameHashLength-len(sep)])
+
+	return joinNameParts(sep, head, hash), nil
+}
+
+func trimNonAlphaNum(s string) string {
+	return strings.TrimFunc(s, func(r rune) bool {
+		return !isAlphaNum(r)
+	})
+}
+
+func isLowerAlphaNum(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9')
+}
+
+func isAlphaNum(r rune) bool {
+	return isLowerAlphaNum(r) || (r >= 'A' && r <= 'Z')
+}
+
+func isDNSLabelRune(r rune) bool {
+	return isLowerAlphaNum(r) || r == '-'
+}
+
+func isLabelValueRune(r rune) bool {
+	return isAlphaNum(r) || r == '-' || r == '_' || r == '.'
+}
@@ -0,0 +1,204 @@
+package render
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func Test_k8sName(t *testing.T) {
+	type args struct {
+		prefix string
+		name   string
+		maxLen int
+	}
+	type want struct {
+		rsp string
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"ShortName": {
+			reason: "Should join prefix and name",
+			args:   args{prefix: "db", name: "example", maxLen: 63},
+			want:   want{rsp: "db-example"},
+		},
+		"NoPrefix": {
+			reason: "Should omit an empty prefix",
+			args:   args{name: "example", maxLen: 63},
+			want:   want{rsp: "example"},
+		},
+		"Sanitize": {
+			reason: "Should lowercase the name and replace invalid characters",
+			args:   args{prefix: "DB_", name: "My.Example--XR.", maxLen: 63},
+			want:   want{rsp: "db-my-example-xr"},
+		},
+		"Truncate": {
+			reason: "Should truncate long names and append a hash suffix",
+			args:   args{prefix: "database", name: "a-very-long-composite-resource-name", maxLen: 20},
+			want:   want{rsp: "database-a-5074b072"},
+		},
+		"MaxLenTooShort": {
+			reason: "Should return an error if there is no room for the hash suffix",
+			args:   args{prefix: "database", name: "example", maxLen: 8},
+			want:   want{err: cmpopts.AnyError},
+		},
+		"Empty": {
+			reason: "Should return an error if no valid characters remain",
+			args:   args{prefix: "_", name: "...", maxLen: 63},
+			want:   want{err: cmpopts.AnyError},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			rsp, err := k8sName(tc.args.prefix, tc.args.name, tc.args.maxLen)
+			if diff := cmp.Diff(tc.want.rsp, rsp); diff != "" {
+				t.Errorf("%s\nk8sName(...): -want rsp, +got rsp:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("%s\nk8sName(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func Test_k8sLabelValue(t *testing.T) {
+	type args struct {
+		value string
+	}
+	type want struct {
+		rsp string
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Valid": {
+			reason: "Should return a valid label value unchanged",
+			args:   args{value: "My_Label.value-1"},
+			want:   want{rsp: "My_Label.value-1"},
+		},
+		"Sanitize": {
+			reason: "Should replace invalid characters and trim non-alphanumeric characters",
+			args:   args{value: "_team/platform engineering."},
+			want:   want{rsp: "team-platform-engineering"},
+		},
+		"Empty": {
+			reason: "Should allow an empty label value",
+			args:   args{value: "//"},
+			want:   want{rsp: ""},
+		},
+		"Truncate": {
+			reason: "Should truncate values longer than 63 characters",
+			args:   args{value: strings.Repeat("a", 70)},
+			want:   want{rsp: strings.Repeat("a", 54) + "-6bd5e503"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			rsp, err := k8sLabelValue(tc.args.value)
+			if diff := cmp.Diff(tc.want.rsp, rsp); diff != "" {
+				t.Errorf("%s\nk8sLabelValue(...): -want rsp, +got rsp:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("%s\nk8sLabelValue(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func Test_s3BucketName(t *testing.T) {
+	type args struct {
+		prefix string
+		name   string
+	}
+	type want struct {
+		rsp string
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Valid": {
+			reason: "Should join prefix and name",
+			args:   args{prefix: "acme", name: "Logs.Archive"},
+			want:   want{rsp: "acme-logs-archive"},
+		},
+		"TooShort": {
+			reason: "Should return an error if the name is shorter than 3 characters",
+			args:   args{name: "a_"},
+			want:   want{err: cmpopts.AnyError},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			rsp, err := s3BucketName(tc.args.prefix, tc.args.name)
+			if diff := cmp.Diff(tc.want.rsp, rsp); diff != "" {
+				t.Errorf("%s\ns3BucketName(...): -want rsp, +got rsp:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("%s\ns3BucketName(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func Test_azureStorageAccountName(t *testing.T) {
+	type args struct {
+		prefix string
+		name   string
+	}
+	type want struct {
+		rsp string
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Valid": {
+			reason: "Should join prefix and name without separators",
+			args:   args{prefix: "acme", name: "Prod-Data"},
+			want:   want{rsp: "acmeproddata"},
+		},
+		"Truncate": {
+			reason: "Should truncate names longer than 24 characters",
+			args:   args{prefix: "acme", name: "production-data-archive-eu"},
+			want:   want{rsp: "acmeproductionda04a36a55"},
+		},
+		"TooShort": {
+			reason: "Should return an error if the name is shorter than 3 characters",
+			args:   args{name: "a-b"},
+			want:   want{err: cmpopts.AnyError},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			rsp, err := azureStorageAccountName(tc.args.prefix, tc.args.name)
+			if diff := cmp.Diff(tc.want.rsp, rsp); diff != "" {
+				t.Errorf("%s\nazureStorageAccountName(...): -want rsp, +got rsp:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("%s\nazureStorageAccountName(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package render
+
+import (
+	"github.com/crossplane/crossplane-runtime/v2/pkg/meta"
+
+	"github.com/crossplane/function-sdk-go/errors"
+	"github.com/crossplane/function-sdk-go/resource/composed"
+	"github.com/crossplane/function-sdk-go/resource/composite"
+)
+
+// Composed resources of a namespaced Crossplane v2 XR must be in the XR's
+// namespace. The function can't tell which kinds are namespaced, so resources
+// that are cluster scoped must be marked with the cluster-scoped annotation.
+
+// setComposedNamespace defaults the namespace of the supplied composed
+// resource to the namespace of the observed XR. It returns an error if the
+// composed resource is in another namespace. Composed resources of cluster
+// scoped XRs and those with the cluster-scoped annotation are not changed,
+// except for removing the annotation.
+func setComposedNamespace(xr *composite.Unstructured, cd *composed.Unstructured) error {
+	scope, found := cd.GetAnnotations()[annotationKeyClusterScoped]
+	if found {
+		if scope != "true" && scope != "false" {
+			return errors.Errorf("invalid %q annotation value %q: must be true or false", annotationKeyClusterScoped, scope)
+		}
+		meta.RemoveAnnotations(cd, annotationKeyClusterScoped)
+	}
+
+	ns := xr.GetNamespace()
+	if ns == "" || scope == "true" {
+		return nil
+	}
+
+	switch cd.GetNamespace() {
+	case "":
+		cd.SetNamespace(ns)
+	case ns:
+	default:
+		return errors.Errorf("%q %q must be in the namespace %q of the composite resource, not %q - set the %q annotation to \"true\" if it is cluster scoped", cd.GetKind(), cd.GetName(), ns, cd.GetNamespace(), annotationKeyClusterScoped)
+	}
+
+	return nil
+}
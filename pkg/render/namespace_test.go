@@ -0,0 +1,89 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/crossplane/function-sdk-go/resource/composed"
+	"github.com/crossplane/function-sdk-go/resource/composite"
+)
+
+func Test_setComposedNamespace(t *testing.T) {
+	xr := func(ns string) *composite.Unstructured {
+		u := composite.New()
+		u.SetNamespace(ns)
+		return u
+	}
+	cd := func(ns string, annotations map[string]string) *composed.Unstructured {
+		u := composed.New()
+		u.SetName("cool-cd")
+		u.SetNamespace(ns)
+		u.SetAnnotations(annotations)
+		return u
+	}
+
+	type args struct {
+		xr *composite.Unstructured
+		cd *composed.Unstructured
+	}
+	type want struct {
+		cd  *composed.Unstructured
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"ClusterScopedComposite": {
+			reason: "Should not change resources composed by cluster scoped composite resources",
+			args:   args{xr: xr(""), cd: cd("", nil)},
+			want:   want{cd: cd("", nil)},
+		},
+		"DefaultNamespace": {
+			reason: "Should default the namespace to the namespace of the composite resource",
+			args:   args{xr: xr("cool-ns"), cd: cd("", nil)},
+			want:   want{cd: cd("cool-ns", nil)},
+		},
+		"SameNamespace": {
+			reason: "Should accept resources in the namespace of the composite resource",
+			args:   args{xr: xr("cool-ns"), cd: cd("cool-ns", nil)},
+			want:   want{cd: cd("cool-ns", nil)},
+		},
+		"OtherNamespace": {
+			reason: "Should return an error if the resource is in another namespace",
+			args:   args{xr: xr("cool-ns"), cd: cd("other-ns", nil)},
+			want:   want{cd: cd("other-ns", nil), err: cmpopts.AnyError},
+		},
+		"ClusterScoped": {
+			reason: "Should not set the namespace of resources marked as cluster scoped and remove the annotation",
+			args:   args{xr: xr("cool-ns"), cd: cd("", map[string]string{annotationKeyClusterScoped: "true"})},
+			want:   want{cd: cd("", map[string]string{})},
+		},
+		"NotClusterScoped": {
+			reason: "Should default the namespace of resources marked as not cluster scoped and remove the annotation",
+			args:   args{xr: xr("cool-ns"), cd: cd("", map[string]string{annotationKeyClusterScoped: "false"})},
+			want:   want{cd: cd("cool-ns", map[string]string{})},
+		},
+		"InvalidAnnotation": {
+			reason: "Should return an error if the cluster-scoped annotation is not true or false",
+			args:   args{xr: xr("cool-ns"), cd: cd("", map[string]string{annotationKeyClusterScoped: "yes"})},
+			want:   want{cd: cd("", map[string]string{annotationKeyClusterScoped: "yes"}), err: cmpopts.AnyError},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := setComposedNamespace(tc.args.xr, tc.args.cd)
+			if diff := cmp.Diff(tc.want.cd, tc.args.cd, cmp.AllowUnexported(unstructured.Unstructured{})); diff != "" {
+				t.Errorf("%s\nsetComposedNamespace(...): -want cd, +got cd:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("%s\nsetComposedNamespace(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package render
+
+import (
+	"slices"
+
+	"dario.cat/mergo"
+	"github.com/crossplane/crossplane-runtime/v2/pkg/fieldpath"
+	opsv1alpha1 "github.com/crossplane/crossplane/apis/v2/ops/v1alpha1"
+
+	"github.com/crossplane/function-sdk-go/errors"
+	fnv1 "github.com/crossplane/function-sdk-go/proto/v1"
+	"github.com/crossplane/function-sdk-go/response"
+
+	"github.com/crossplane-contrib/function-go-templating/input/v1beta1"
+)
+
+// Crossplane v2 also runs functions in Operations. Operation requests have no
+// composite resource. Desired resources are applied as they are, and the
+// function may return output that is recorded in the Operation's status.
+
+// isOperationRequest reports whether the request map was sent by an Operation
+// rather than a Composition. Crossplane doesn't mark Operation requests
+// explicitly, but always sends the observed composite resource to
+// Compositions. A request without a composite is only treated as an Operation
+// if it includes the resource that triggered a WatchOperation, or if Crossplane
+// advertises its capabilities, i.e. is v2.2 or later. Other requests without a
+// composite, like those of older tests or local renders, are Composition
+// requests unless the input sets the Operation mode.
+func isOperationRequest(req map[string]any) bool {
+	if getCompositeResource(req) != nil {
+		return false
+	}
+	if getWatchedResource(req) != nil {
+		return true
+	}
+
+	var capabilities []string
+	_ = fieldpath.Pave(req).GetValueInto("meta.capabilities", &capabilities)
+
+	return slices.Contains(capabilities, fnv1.Capability_CAPABILITY_CAPABILITIES.String())
+}
+
+// isOperation reports whether the templates are rendered for an Operation,
+// according to the supplied mode.
+func isOperation(mode v1beta1.Mode, req map[string]any) bool {
+	switch mode {
+	case v1beta1.OperationMode:
+		return true
+	case v1beta1.CompositionMode:
+		return false
+	case v1beta1.AutoMode:
+	}
+
+	return isOperationRequest(req)
+}
+
+// getWatchedResource returns the resource that triggered the Operation of a
+// WatchOperation. It returns nil for other requests.
+func getWatchedResource(req map[string]any) map[string]any {
+	res := extraResourcesOf(req, opsv1alpha1.RequirementNameWatchedResource)
+	if len(res) == 0 {
+		return nil
+	}
+
+	return res[0]
+}
+
+// MergeOperationOutput merges the supplied values into the output of rsp.
+func MergeOperationOutput(rsp *fnv1.RunFunctionResponse, val map[string]any) error {
+	output := rsp.GetOutput().AsMap()
+	if err := mergo.Merge(&output, val, mergo.WithOverride); err != nil {
+		return errors.Wrap(err, "cannot merge operation output")
+	}
+
+	return errors.Wrap(response.SetOutput(rsp, output), "cannot set operation output")
+}
@@ -0,0 +1,111 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	fnv1 "github.com/crossplane/function-sdk-go/proto/v1"
+	"github.com/crossplane/function-sdk-go/resource"
+)
+
+func Test_isOperationRequest(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		req    map[string]any
+		want   bool
+	}{
+		"Composition": {
+			reason: "Requests with an observed composite are Composition requests",
+			req: map[string]any{
+				"meta":     map[string]any{"capabilities": []any{"CAPABILITY_CAPABILITIES"}},
+				"observed": map[string]any{"composite": map[string]any{"resource": map[string]any{"kind": "XR"}}},
+			},
+			want: false,
+		},
+		"WatchOperation": {
+			reason: "Requests with a watched resource are Operation requests",
+			req: map[string]any{
+				"extraResources": map[string]any{
+					"ops.crossplane.io/watched-resource": map[string]any{
+						"items": []any{map[string]any{"resource": map[string]any{"kind": "ConfigMap"}}},
+					},
+				},
+			},
+			want: true,
+		},
+		"Operation": {
+			reason: "Requests without a composite from a Crossplane that advertises its capabilities are Operation requests",
+			req:    map[string]any{"meta": map[string]any{"capabilities": []any{"CAPABILITY_CAPABILITIES"}}},
+			want:   true,
+		},
+		"NoCapabilities": {
+			reason: "Requests without a composite and without capabilities are Composition requests",
+			req:    map[string]any{},
+			want:   false,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := isOperationRequest(tc.req)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("%s\nisOperationRequest(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func Test_getWatchedResource(t *testing.T) {
+	cm := map[string]any{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]any{"name": "cool-cm"},
+	}
+
+	cases := map[string]struct {
+		reason string
+		req    map[string]any
+		want   map[string]any
+	}{
+		"WatchOperation": {
+			reason: "Should return the watched resource",
+			req: map[string]any{
+				"requiredResources": map[string]any{
+					"ops.crossplane.io/watched-resource": map[string]any{
+						"items": []any{map[string]any{"resource": cm}},
+					},
+				},
+			},
+			want: cm,
+		},
+		"OtherRequest": {
+			reason: "Should return nil if there is no watched resource",
+			req:    map[string]any{},
+			want:   nil,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := getWatchedResource(tc.req)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("%s\ngetWatchedResource(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestMergeOperationOutput(t *testing.T) {
+	rsp := &fnv1.RunFunctionResponse{
+		Output: resource.MustStructJSON(`{"a":"old","nested":{"b":"b"}}`),
+	}
+
+	if err := MergeOperationOutput(rsp, map[string]any{"a": "new", "nested": map[string]any{"c": "c"}}); err != nil {
+		t.Fatalf("MergeOperationOutput(...): unexpected error: %v", err)
+	}
+
+	want := resource.MustStructJSON(`{"a":"new","nested":{"b":"b","c":"c"}}`)
+	if diff := cmp.Diff(want, rsp.GetOutput(), protocmp.Transform()); diff != "" {
+		t.Errorf("MergeOperationOutput(...): -want, +got:\n%s", diff)
+	}
+}
@@ -0,0 +1,152 @@
+package render
+
+import (
+	"math"
+	"reflect"
+	"strconv"
+
+	"gopkg.in/inf.v0"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/crossplane/function-sdk-go/errors"
+)
+
+// toQuantity parses v as a Kubernetes resource.Quantity. It accepts strings
+// like "2Gi" or "500m" as well as plain numbers.
+func toQuantity(v any) (resource.Quantity, error) {
+	var s string
+	switch t := v.(type) {
+	case string:
+		s = t
+	case int:
+		s = strconv.Itoa(t)
+	case int64:
+		s = strconv.FormatInt(t, 10)
+	case float64:
+		s = strconv.FormatFloat(t, 'f', -1, 64)
+	default:
+		return resource.Quantity{}, errors.Errorf("cannot parse %v (%T) as a quantity", v, v)
+	}
+
+	q, err := resource.ParseQuantity(s)
+	if err != nil {
+		return resource.Quantity{}, errors.Wrapf(err, "cannot parse %q as a quantity", s)
+	}
+
+	return q, nil
+}
+
+// quantityAdd returns the sum of the supplied quantities, in the format of the
+// first one.
+func quantityAdd(first any, rest ...any) (string, error) {
+	sum, err := toQuantity(first)
+	if err != nil {
+		return "", err
+	}
+	for _, v := range rest {
+		q, err := toQuantity(v)
+		if err != nil {
+			return "", err
+		}
+		sum.Add(q)
+	}
+
+	return sum.String(), nil
+}
+
+// quantitySum returns the sum of a list of quantities. It returns "0" for an
+// empty list.
+func quantitySum(list any) (string, error) {
+	v := reflect.ValueOf(list)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return "", errors.Errorf("quantitySum requires a list, got %T", list)
+	}
+	if v.Len() == 0 {
+		return "0", nil
+	}
+
+	rest := make([]any, 0, v.Len()-1)
+	for i := 1; i < v.Len(); i++ {
+		rest = append(rest, v.Index(i).Interface())
+	}
+
+	return quantityAdd(v.Index(0).Interface(), rest...)
+}
+
+// quantitySub returns a minus b, in the format of a.
+func quantitySub(a, b any) (string, error) {
+	qa, err := toQuantity(a)
+	if err != nil {
+		return "", err
+	}
+	qb, err := toQuantity(b)
+	if err != nil {
+		return "", err
+	}
+	qa.Sub(qb)
+
+	return qa.String(), nil
+}
+
+// quantityMul returns q multiplied by factor, in the format of q.
+func quantityMul(q, factor any) (string, error) {
+	qq, err := toQuantity(q)
+	if err != nil {
+		return "", err
+	}
+	f, err := toQuantity(factor)
+	if err != nil {
+		return "", errors.Wrap(err, "invalid factor")
+	}
+
+	product := new(inf.Dec).Mul(qq.AsDec(), f.AsDec())
+
+	return resource.NewDecimalQuantity(*product, qq.Format).String(), nil
+}
+
+// quantityCmp returns -1, 0 or 1 if a is less than, equal to or greater than
+// b.
+func quantityCmp(a, b any) (int, error) {
+	qa, err := toQuantity(a)
+	if err != nil {
+		return 0, err
+	}
+	qb, err := toQuantity(b)
+	if err != nil {
+		return 0, err
+	}
+
+	return qa.Cmp(qb), nil
+}
+
+// quantityConvert returns the value of q in the supplied unit, e.g. 2048 for
+// "2Gi" in "Mi". An empty unit returns the value in base units. Whole values
+// are returned as integers.
+func quantityConvert(q any, unit string) (any, error) {
+	qq, err := toQuantity(q)
+	if err != nil {
+		return 0, err
+	}
+	u, err := resource.ParseQuantity("1" + unit)
+	if err != nil {
+		return 0, errors.Wrapf(err, "invalid unit %q", unit)
+	}
+
+	res := new(inf.Dec).QuoRound(qq.AsDec(), u.AsDec(), 9, inf.RoundHalfUp)
+	f, err := strconv.ParseFloat(res.String(), 64)
+	if err != nil {
+		return 0, errors.Wrapf(err, "cannot convert %s to %s", qq.String(), unit)
+	}
+
+	return toNumber(f), nil
+}
+
+// toNumber returns f as an int64 if it's a whole number, so that templates
+// render large values like 1048576 without an exponent.
+func toNumber(f float64) any {
+	if f == math.Trunc(f) && math.Abs(f) < 1<<53 {
+		return int64(f)
+	}
+
+	return f
+}
@@ -0,0 +1,284 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func Test_quantityAdd(t *testing.T) {
+	type args struct {
+		first any
+		rest  []any
+	}
+	type want struct {
+		rsp string
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"BinarySI": {
+			reason: "Should add binary SI quantities",
+			args:   args{first: "2Gi", rest: []any{"512Mi", "512Mi"}},
+			want:   want{rsp: "3Gi"},
+		},
+		"DecimalSI": {
+			reason: "Should add decimal SI quantities",
+			args:   args{first: "500m", rest: []any{"1.5"}},
+			want:   want{rsp: "2"},
+		},
+		"Numbers": {
+			reason: "Should accept numbers read from the request",
+			args:   args{first: int64(1), rest: []any{float64(0.5)}},
+			want:   want{rsp: "1500m"},
+		},
+		"Invalid": {
+			reason: "Should return an error if a quantity is invalid",
+			args:   args{first: "2Gi", rest: []any{"lots"}},
+			want:   want{err: cmpopts.AnyError},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			rsp, err := quantityAdd(tc.args.first, tc.args.rest...)
+			if diff := cmp.Diff(tc.want.rsp, rsp); diff != "" {
+				t.Errorf("%s\nquantityAdd(...): -want rsp, +got rsp:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("%s\nquantityAdd(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func Test_quantitySum(t *testing.T) {
+	type want struct {
+		rsp string
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		list   any
+		want   want
+	}{
+		"Sum": {
+			reason: "Should sum a list of quantities",
+			list:   []any{"256Mi", "256Mi", "512Mi"},
+			want:   want{rsp: "1Gi"},
+		},
+		"Empty": {
+			reason: "Should return zero for an empty list",
+			list:   []any{},
+			want:   want{rsp: "0"},
+		},
+		"NotAList": {
+			reason: "Should return an error if the argument is not a list",
+			list:   "1Gi",
+			want:   want{err: cmpopts.AnyError},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			rsp, err := quantitySum(tc.list)
+			if diff := cmp.Diff(tc.want.rsp, rsp); diff != "" {
+				t.Errorf("%s\nquantitySum(...): -want rsp, +got rsp:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("%s\nquantitySum(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func Test_quantitySub(t *testing.T) {
+	type args struct {
+		a any
+		b any
+	}
+	type want struct {
+		rsp string
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Sub": {
+			reason: "Should subtract quantities",
+			args:   args{a: "4Gi", b: "1536Mi"},
+			want:   want{rsp: "2560Mi"},
+		},
+		"Negative": {
+			reason: "Should return negative quantities",
+			args:   args{a: "1", b: "1500m"},
+			want:   want{rsp: "-500m"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			rsp, err := quantitySub(tc.args.a, tc.args.b)
+			if diff := cmp.Diff(tc.want.rsp, rsp); diff != "" {
+				t.Errorf("%s\nquantitySub(...): -want rsp, +got rsp:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("%s\nquantitySub(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func Test_quantityMul(t *testing.T) {
+	type args struct {
+		q      any
+		factor any
+	}
+	type want struct {
+		rsp string
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Replicas": {
+			reason: "Should multiply a quantity by a replica count",
+			args:   args{q: "512Mi", factor: float64(3)},
+			want:   want{rsp: "1536Mi"},
+		},
+		"Fraction": {
+			reason: "Should multiply a quantity by a fraction",
+			args:   args{q: "2", factor: "0.25"},
+			want:   want{rsp: "500m"},
+		},
+		"InvalidFactor": {
+			reason: "Should return an error if the factor is invalid",
+			args:   args{q: "2", factor: true},
+			want:   want{err: cmpopts.AnyError},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			rsp, err := quantityMul(tc.args.q, tc.args.factor)
+			if diff := cmp.Diff(tc.want.rsp, rsp); diff != "" {
+				t.Errorf("%s\nquantityMul(...): -want rsp, +got rsp:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("%s\nquantityMul(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func Test_quantityCmp(t *testing.T) {
+	type args struct {
+		a any
+		b any
+	}
+	type want struct {
+		rsp int
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Less": {
+			reason: "Should return -1 if a is less than b",
+			args:   args{a: "1000Mi", b: "1Gi"},
+			want:   want{rsp: -1},
+		},
+		"Equal": {
+			reason: "Should return 0 if a equals b in different units",
+			args:   args{a: "1024Mi", b: "1Gi"},
+			want:   want{rsp: 0},
+		},
+		"Greater": {
+			reason: "Should return 1 if a is greater than b",
+			args:   args{a: "1G", b: "900M"},
+			want:   want{rsp: 1},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			rsp, err := quantityCmp(tc.args.a, tc.args.b)
+			if diff := cmp.Diff(tc.want.rsp, rsp); diff != "" {
+				t.Errorf("%s\nquantityCmp(...): -want rsp, +got rsp:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("%s\nquantityCmp(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func Test_quantityConvert(t *testing.T) {
+	type args struct {
+		q    any
+		unit string
+	}
+	type want struct {
+		rsp any
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"GiToMi": {
+			reason: "Should convert Gi to Mi",
+			args:   args{q: "2Gi", unit: "Mi"},
+			want:   want{rsp: int64(2048)},
+		},
+		"TiToMi": {
+			reason: "Should return large whole values as integers",
+			args:   args{q: "1Ti", unit: "Mi"},
+			want:   want{rsp: int64(1048576)},
+		},
+		"MiToGi": {
+			reason: "Should return fractions",
+			args:   args{q: "1536Mi", unit: "Gi"},
+			want:   want{rsp: 1.5},
+		},
+		"MilliCPU": {
+			reason: "Should convert to base units if the unit is empty",
+			args:   args{q: "250m", unit: ""},
+			want:   want{rsp: 0.25},
+		},
+		"InvalidUnit": {
+			reason: "Should return an error if the unit is invalid",
+			args:   args{q: "2Gi", unit: "GB"},
+			want:   want{rsp: 0, err: cmpopts.AnyError},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			rsp, err := quantityConvert(tc.args.q, tc.args.unit)
+			if diff := cmp.Diff(tc.want.rsp, rsp); diff != "" {
+				t.Errorf("%s\nquantityConvert(...): -want rsp, +got rsp:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("%s\nquantityConvert(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
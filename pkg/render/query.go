@@ -0,0 +1,148 @@
+package render
+
+import (
+	"encoding/json"
+	"maps"
+	"slices"
+
+	"github.com/itchyny/gojq"
+	"k8s.io/client-go/util/jsonpath"
+
+	"github.com/crossplane/function-sdk-go/errors"
+)
+
+// jq runs the supplied jq query against data and returns its result. Optional
+// maps of variables are made available to the query, e.g. {"region": "eu"} is
+// available as $region. Queries that produce more than one value must collect
+// their results into an array, e.g. [.[] | select(...)].
+func jq(query string, data any, vars ...map[string]any) (any, error) {
+	q, err := gojq.Parse(query)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot parse jq query %q", query)
+	}
+
+	merged := make(map[string]any)
+	for _, v := range vars {
+		maps.Copy(merged, v)
+	}
+	names := slices.Sorted(maps.Keys(merged))
+
+	values := make([]any, 0, len(names))
+	varNames := make([]string, 0, len(names))
+	for _, k := range names {
+		v, err := normalizeValue(merged[k])
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot convert jq variable %q", k)
+		}
+		values = append(values, v)
+		varNames = append(varNames, "$"+k)
+	}
+
+	code, err := gojq.Compile(q, gojq.WithVariables(varNames))
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot compile jq query %q", query)
+	}
+
+	in, err := normalizeValue(data)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot convert jq input")
+	}
+
+	var res []any
+	iter := code.Run(in, values...)
+	for {
+		v, ok := iter.Next()
+		if !ok {
+			break
+		}
+		if err, ok := v.(error); ok {
+			var herr *gojq.HaltError
+			if errors.As(err, &herr) && herr.Value() == nil {
+				break
+			}
+			return nil, errors.Wrapf(err, "cannot run jq query %q", query)
+		}
+		res = append(res, v)
+	}
+
+	switch len(res) {
+	case 0:
+		return nil, nil
+	case 1:
+		return res[0], nil
+	default:
+		return nil, errors.Errorf("jq query %q produced %d values, wrap it in [] to collect them into an array", query, len(res))
+	}
+}
+
+// normalizeValue converts v to plain JSON types, which is what gojq supports.
+// Numbers in the request are int64s, which are converted to ints.
+func normalizeValue(v any) (any, error) {
+	switch t := v.(type) {
+	case nil, bool, int, float64, string:
+		return t, nil
+	case int64:
+		return int(t), nil
+	case int32:
+		return int(t), nil
+	case float32:
+		return float64(t), nil
+	case []any:
+		out := make([]any, len(t))
+		for i, e := range t {
+			n, err := normalizeValue(e)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = n
+		}
+		return out, nil
+	case map[string]any:
+		out := make(map[string]any, len(t))
+		for k, e := range t {
+			n, err := normalizeValue(e)
+			if err != nil {
+				return nil, err
+			}
+			out[k] = n
+		}
+		return out, nil
+	}
+
+	// Fall back to a JSON round trip for any other type, e.g. []string.
+	j, err := json.Marshal(v)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot marshal %T to json", v)
+	}
+	var out any
+	if err := json.Unmarshal(j, &out); err != nil {
+		return nil, errors.Wrapf(err, "cannot unmarshal %T from json", v)
+	}
+
+	return out, nil
+}
+
+// jsonPath runs the supplied JSONPath expression, in the same syntax as
+// kubectl's -o jsonpath, against data and returns all matching values.
+func jsonPath(expr string, data any) ([]any, error) {
+	jp := jsonpath.New("jsonpath").AllowMissingKeys(true)
+	if err := jp.Parse(expr); err != nil {
+		return nil, errors.Wrapf(err, "cannot parse JSONPath expression %q", expr)
+	}
+
+	results, err := jp.FindResults(data)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot run JSONPath expression %q", expr)
+	}
+
+	res := make([]any, 0)
+	for _, r := range results {
+		for _, v := range r {
+			if v.IsValid() && v.CanInterface() {
+				res = append(res, v.Interface())
+			}
+		}
+	}
+
+	return res, nil
+}
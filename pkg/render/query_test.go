@@ -0,0 +1,189 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func Test_jq(t *testing.T) {
+	resources := []any{
+		map[string]any{
+			"metadata": map[string]any{"name": "eu-1"},
+			"status":   map[string]any{"atProvider": map[string]any{"region": "eu-west-1"}},
+			"spec":     map[string]any{"replicas": int64(3)},
+		},
+		map[string]any{
+			"metadata": map[string]any{"name": "us-1"},
+			"status":   map[string]any{"atProvider": map[string]any{"region": "us-east-1"}},
+			"spec":     map[string]any{"replicas": int64(1)},
+		},
+		map[string]any{
+			"metadata": map[string]any{"name": "eu-2"},
+			"status":   map[string]any{"atProvider": map[string]any{"region": "eu-west-1"}},
+			"spec":     map[string]any{"replicas": int64(2)},
+		},
+	}
+
+	type args struct {
+		query string
+		data  any
+		vars  []map[string]any
+	}
+	type want struct {
+		rsp any
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Select": {
+			reason: "Should filter and project the input",
+			args: args{
+				query: `[.[] | select(.status.atProvider.region == $region) | .metadata.name]`,
+				data:  resources,
+				vars:  []map[string]any{{"region": "eu-west-1"}},
+			},
+			want: want{rsp: []any{"eu-1", "eu-2"}},
+		},
+		"Numbers": {
+			reason: "Should support int64 numbers from the request",
+			args: args{
+				query: `map(.spec.replicas) | add`,
+				data:  resources,
+			},
+			want: want{rsp: 6},
+		},
+		"ConvertedInput": {
+			reason: "Should convert input types that are not supported by jq",
+			args: args{
+				query: `length`,
+				data:  []string{"a", "b"},
+			},
+			want: want{rsp: 2},
+		},
+		"NoResult": {
+			reason: "Should return nil if the query produces no values",
+			args: args{
+				query: `.[] | select(.metadata.name == "missing")`,
+				data:  resources,
+			},
+			want: want{rsp: nil},
+		},
+		"MultipleResults": {
+			reason: "Should return an error if the query produces more than one value",
+			args: args{
+				query: `.[] | .metadata.name`,
+				data:  resources,
+			},
+			want: want{err: cmpopts.AnyError},
+		},
+		"InvalidQuery": {
+			reason: "Should return an error if the query cannot be parsed",
+			args: args{
+				query: `.[`,
+				data:  resources,
+			},
+			want: want{err: cmpopts.AnyError},
+		},
+		"RuntimeError": {
+			reason: "Should return an error if the query fails",
+			args: args{
+				query: `error("boom")`,
+				data:  resources,
+			},
+			want: want{err: cmpopts.AnyError},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			rsp, err := jq(tc.args.query, tc.args.data, tc.args.vars...)
+			if diff := cmp.Diff(tc.want.rsp, rsp); diff != "" {
+				t.Errorf("%s\njq(...): -want rsp, +got rsp:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("%s\njq(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func Test_jsonPath(t *testing.T) {
+	data := map[string]any{
+		"items": []any{
+			map[string]any{
+				"metadata": map[string]any{"name": "eu-1"},
+				"status":   map[string]any{"atProvider": map[string]any{"region": "eu-west-1"}},
+			},
+			map[string]any{
+				"metadata": map[string]any{"name": "us-1"},
+				"status":   map[string]any{"atProvider": map[string]any{"region": "us-east-1"}},
+			},
+		},
+	}
+
+	type args struct {
+		expr string
+		data any
+	}
+	type want struct {
+		rsp []any
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Filter": {
+			reason: "Should return all matching values",
+			args: args{
+				expr: `{.items[?(@.status.atProvider.region=="eu-west-1")].metadata.name}`,
+				data: data,
+			},
+			want: want{rsp: []any{"eu-1"}},
+		},
+		"Wildcard": {
+			reason: "Should return values of all list items",
+			args: args{
+				expr: `{.items[*].metadata.name}`,
+				data: data,
+			},
+			want: want{rsp: []any{"eu-1", "us-1"}},
+		},
+		"Missing": {
+			reason: "Should return an empty list if nothing matches",
+			args: args{
+				expr: `{.missing}`,
+				data: data,
+			},
+			want: want{rsp: []any{}},
+		},
+		"InvalidExpression": {
+			reason: "Should return an error if the expression cannot be parsed",
+			args: args{
+				expr: `{.items[}`,
+				data: data,
+			},
+			want: want{err: cmpopts.AnyError},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			rsp, err := jsonPath(tc.args.expr, tc.args.data)
+			if diff := cmp.Diff(tc.want.rsp, rsp); diff != "" {
+				t.Errorf("%s\njsonPath(...): -want rsp, +got rsp:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("%s\njsonPath(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package render
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/crossplane/function-sdk-go/errors"
+)
+
+// The readiness functions select composed resources by target. A target is
+// either the composition resource name of a resource, a list of names, or a
+// filter like the one accepted by listComposedResources. Filters select from
+// both the observed and the desired composed resources, so resources that
+// were not yet created are considered as well. Resources that are not
+// observed are neither ready nor synced.
+
+// isReady reports whether the resources selected by target are all ready. It
+// returns false if target selects no resources.
+func isReady(req map[string]any, target any) (bool, error) {
+	return allHaveCondition(req, "Ready", target)
+}
+
+// isSynced reports whether the resources selected by target are all synced.
+// It returns false if target selects no resources.
+func isSynced(req map[string]any, target any) (bool, error) {
+	return allHaveCondition(req, "Synced", target)
+}
+
+// allReady reports whether the resources selected by targets are all ready.
+// Without targets it checks all observed and desired composed resources.
+func allReady(req map[string]any, targets ...any) (bool, error) {
+	return allHaveCondition(req, "Ready", targets...)
+}
+
+// readyCount returns the number of ready resources selected by targets.
+// Without targets it counts all observed and desired composed resources.
+func readyCount(req map[string]any, targets ...any) (int, error) {
+	names, err := selectComposedResources(req, targets...)
+	if err != nil {
+		return 0, err
+	}
+
+	n := 0
+	for _, name := range names {
+		if hasObservedCondition(req, name, "Ready") {
+			n++
+		}
+	}
+
+	return n, nil
+}
+
+func allHaveCondition(req map[string]any, ct string, targets ...any) (bool, error) {
+	names, err := selectComposedResources(req, targets...)
+	if err != nil {
+		return false, err
+	}
+	if len(names) == 0 {
+		return false, nil
+	}
+
+	for _, name := range names {
+		if !hasObservedCondition(req, name, ct) {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// hasObservedCondition reports whether the observed composed resource has a
+// condition of the supplied type with status True.
+func hasObservedCondition(req map[string]any, name, ct string) bool {
+	r := getComposedResource(req, name)
+	if r == nil {
+		return false
+	}
+
+	return getResourceCondition(ct, r).Status == corev1.ConditionTrue
+}
+
+// selectComposedResources returns the sorted, unique names of the composed
+// resources selected by targets.
+func selectComposedResources(req map[string]any, targets ...any) ([]string, error) {
+	if len(targets) == 0 {
+		targets = []any{map[string]any{}}
+	}
+
+	names := make(map[string]bool)
+	for _, t := range targets {
+		switch v := t.(type) {
+		case string:
+			names[v] = true
+		case []string:
+			for _, n := range v {
+				names[n] = true
+			}
+		case []any:
+			for _, n := range v {
+				names[fmt.Sprint(n)] = true
+			}
+		case map[string]any:
+			for _, state := range []string{"observed", "desired"} {
+				res, err := listComposedResources(req, state, v)
+				if err != nil {
+					return nil, err
+				}
+				for n := range res {
+					names[n] = true
+				}
+			}
+		default:
+			return nil, errors.Errorf("invalid target of type %T: must be a resource name, a list of names or a filter", t)
+		}
+	}
+
+	return slices.Sorted(maps.Keys(names)), nil
+}
@@ -0,0 +1,219 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func readinessRequest() map[string]any {
+	resource := func(kind string, ready, synced string) map[string]any {
+		return map[string]any{
+			"resource": map[string]any{
+				"apiVersion": "example.org/v1",
+				"kind":       kind,
+				"status": map[string]any{
+					"conditions": []any{
+						map[string]any{"type": "Ready", "status": ready},
+						map[string]any{"type": "Synced", "status": synced},
+					},
+				},
+			},
+		}
+	}
+
+	return map[string]any{
+		"observed": map[string]any{
+			"resources": map[string]any{
+				"bucket-a": resource("Bucket", "True", "True"),
+				"bucket-b": resource("Bucket", "False", "True"),
+				"role":     resource("Role", "True", "False"),
+			},
+		},
+		"desired": map[string]any{
+			"resources": map[string]any{
+				"bucket-a": map[string]any{"resource": map[string]any{"kind": "Bucket"}},
+				"bucket-b": map[string]any{"resource": map[string]any{"kind": "Bucket"}},
+				"role":     map[string]any{"resource": map[string]any{"kind": "Role"}},
+				"policy":   map[string]any{"resource": map[string]any{"kind": "Policy"}},
+			},
+		},
+	}
+}
+
+func Test_isReady(t *testing.T) {
+	type want struct {
+		rsp bool
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		target any
+		want   want
+	}{
+		"ReadyByName": {
+			reason: "Should return true for a ready resource",
+			target: "bucket-a",
+			want:   want{rsp: true},
+		},
+		"NotReadyByName": {
+			reason: "Should return false for a resource that is not ready",
+			target: "bucket-b",
+			want:   want{rsp: false},
+		},
+		"NotObserved": {
+			reason: "Should return false for a desired resource that is not observed yet",
+			target: "policy",
+			want:   want{rsp: false},
+		},
+		"Missing": {
+			reason: "Should return false for an unknown resource",
+			target: "unknown",
+			want:   want{rsp: false},
+		},
+		"ReadyByNames": {
+			reason: "Should return true if all named resources are ready",
+			target: []any{"bucket-a", "role"},
+			want:   want{rsp: true},
+		},
+		"NotReadyByFilter": {
+			reason: "Should return false if any resource matching the filter is not ready",
+			target: map[string]any{"kind": "Bucket"},
+			want:   want{rsp: false},
+		},
+		"NoMatch": {
+			reason: "Should return false if the filter matches no resources",
+			target: map[string]any{"kind": "Topic"},
+			want:   want{rsp: false},
+		},
+		"InvalidTarget": {
+			reason: "Should return an error for unsupported targets",
+			target: 42,
+			want:   want{err: cmpopts.AnyError},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			rsp, err := isReady(readinessRequest(), tc.target)
+			if diff := cmp.Diff(tc.want.rsp, rsp); diff != "" {
+				t.Errorf("%s\nisReady(...): -want rsp, +got rsp:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("%s\nisReady(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func Test_isSynced(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		target any
+		want   bool
+	}{
+		"Synced": {
+			reason: "Should return true if all selected resources are synced",
+			target: map[string]any{"kind": "Bucket"},
+			want:   true,
+		},
+		"NotSynced": {
+			reason: "Should return false for a resource that is not synced",
+			target: "role",
+			want:   false,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			rsp, err := isSynced(readinessRequest(), tc.target)
+			if err != nil {
+				t.Fatalf("%s\nisSynced(...): unexpected error: %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want, rsp); diff != "" {
+				t.Errorf("%s\nisSynced(...): -want rsp, +got rsp:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func Test_allReady(t *testing.T) {
+	cases := map[string]struct {
+		reason  string
+		req     map[string]any
+		targets []any
+		want    bool
+	}{
+		"AllResources": {
+			reason: "Should check all observed and desired resources without targets",
+			req:    readinessRequest(),
+			want:   false,
+		},
+		"Names": {
+			reason:  "Should return true if all named resources are ready",
+			req:     readinessRequest(),
+			targets: []any{"bucket-a", "role"},
+			want:    true,
+		},
+		"NoResources": {
+			reason: "Should return false if there are no composed resources",
+			req:    map[string]any{},
+			want:   false,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			rsp, err := allReady(tc.req, tc.targets...)
+			if err != nil {
+				t.Fatalf("%s\nallReady(...): unexpected error: %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want, rsp); diff != "" {
+				t.Errorf("%s\nallReady(...): -want rsp, +got rsp:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func Test_readyCount(t *testing.T) {
+	type want struct {
+		rsp int
+		err error
+	}
+
+	cases := map[string]struct {
+		reason  string
+		targets []any
+		want    want
+	}{
+		"AllResources": {
+			reason: "Should count all ready resources without targets",
+			want:   want{rsp: 2},
+		},
+		"Filter": {
+			reason:  "Should count the ready resources matching the filter",
+			targets: []any{map[string]any{"kind": "Bucket"}},
+			want:    want{rsp: 1},
+		},
+		"NamesAndFilter": {
+			reason:  "Should count each selected resource once",
+			targets: []any{"bucket-a", "policy", map[string]any{"kind": "Bucket"}},
+			want:    want{rsp: 1},
+		},
+		"InvalidFilter": {
+			reason:  "Should return an error for an invalid filter",
+			targets: []any{map[string]any{"name": "bucket-a"}},
+			want:    want{err: cmpopts.AnyError},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			rsp, err := readyCount(readinessRequest(), tc.targets...)
+			if diff := cmp.Diff(tc.want.rsp, rsp); diff != "" {
+				t.Errorf("%s\nreadyCount(...): -want rsp, +got rsp:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("%s\nreadyCount(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+// Package render renders composed resources from Go templates. It implements
+// the logic of function-go-templating, so that other functions and tools can
+// reuse it.
+package render
+
+import (
+	"io/fs"
+	"text/template"
+	"time"
+
+	"google.golang.org/protobuf/types/known/structpb"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/crossplane-contrib/function-go-templating/input/v1beta1"
+
+	"github.com/crossplane/function-sdk-go/logging"
+	fnv1 "github.com/crossplane/function-sdk-go/proto/v1"
+	"github.com/crossplane/function-sdk-go/response"
+)
+
+// A MetaKindHandler handles a rendered resource of a custom kind of the
+// meta.gotemplating.fn.crossplane.io/v1alpha1 API version, e.g. by updating
+// the response. Returned errors are reported like problems with the rendered
+// document.
+type MetaKindHandler func(req *fnv1.RunFunctionRequest, rsp *fnv1.RunFunctionResponse, obj *unstructured.Unstructured) error
+
+// A Renderer renders the templates of a GoTemplate input.
+type Renderer struct {
+	log            logging.Logger
+	fsys           fs.FS
+	ttl            time.Duration
+	defaultSource  string
+	defaultOptions string
+
+	sources   map[v1beta1.TemplateSource]TemplateSourceFunc
+	funcs     []template.FuncMap
+	metaKinds map[string]MetaKindHandler
+}
+
+// An Option configures a Renderer.
+type Option func(r *Renderer)
+
+// WithLogger configures the logger of the Renderer.
+func WithLogger(log logging.Logger) Option {
+	return func(r *Renderer) {
+		r.log = log
+	}
+}
+
+// WithFS configures the file system templates, schemas and credentials are
+// read from. The default is the file system of the operating system.
+func WithFS(fsys fs.FS) Option {
+	return func(r *Renderer) {
+		r.fsys = fsys
+	}
+}
+
+// WithTTL configures the default TTL of responses.
+func WithTTL(ttl time.Duration) Option {
+	return func(r *Renderer) {
+		r.ttl = ttl
+	}
+}
+
+// WithDefaultSource configures the directory templates are read from when the
+// input has no source.
+func WithDefaultSource(dir string) Option {
+	return func(r *Renderer) {
+		r.defaultSource = dir
+	}
+}
+
+// WithDefaultOptions configures the comma-separated template options used when
+// the input has no options.
+func WithDefaultOptions(options string) Option {
+	return func(r *Renderer) {
+		r.defaultOptions = options
+	}
+}
+
+// WithTemplateSource adds a template source, or replaces a built-in one.
+func WithTemplateSource(source v1beta1.TemplateSource, fn TemplateSourceFunc) Option {
+	return func(r *Renderer) {
+		r.sources[source] = fn
+	}
+}
+
+// WithFunctions adds template functions. They take precedence over all other
+// functions, including include, tpl, the credential functions and the
+// functions of extensions.
+func WithFunctions(funcs template.FuncMap) Option {
+	return func(r *Renderer) {
+		r.funcs = append(r.funcs, funcs)
+	}
+}
+
+// WithMetaKind adds a kind of the meta.gotemplating.fn.crossplane.io/v1alpha1
+// API version. The built-in kinds can't be replaced.
+func WithMetaKind(kind string, h MetaKindHandler) Option {
+	return func(r *Renderer) {
+		r.metaKinds[kind] = h
+	}
+}
+
+// New returns a Renderer configured with the supplied options.
+func New(opts ...Option) *Renderer {
+	r := &Renderer{
+		log:       logging.NewNopLogger(),
+		fsys:      &osFS{},
+		ttl:       response.DefaultTTL,
+		sources:   make(map[v1beta1.TemplateSource]TemplateSourceFunc),
+		metaKinds: make(map[string]MetaKindHandler),
+	}
+	for _, o := range opts {
+		o(r)
+	}
+
+	return r
+}
+
+// templateSourceGetter returns the TemplateGetter of the input's source.
+func (f *Renderer) templateSourceGetter(ctx *structpb.Struct, in *v1beta1.GoTemplate) (TemplateGetter, error) {
+	if fn, ok := f.sources[in.Source]; ok {
+		return fn(f.fsys, ctx, in)
+	}
+
+	return NewTemplateSourceGetter(f.fsys, ctx, in)
+}
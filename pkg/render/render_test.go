@@ -0,0 +1,144 @@
+package render
+
+import (
+	"context"
+	"io/fs"
+	"testing"
+	"text/template"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/structpb"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/crossplane-contrib/function-go-templating/input/v1beta1"
+
+	"github.com/crossplane/function-sdk-go/errors"
+	fnv1 "github.com/crossplane/function-sdk-go/proto/v1"
+	"github.com/crossplane/function-sdk-go/resource"
+	"github.com/crossplane/function-sdk-go/response"
+)
+
+func TestRenderer(t *testing.T) {
+	const custom = v1beta1.TemplateSource("Custom")
+	customSource := func(_ fs.FS, _ *structpb.Struct, _ *v1beta1.GoTemplate) (TemplateGetter, error) {
+		t := `{"apiVersion":"example.org/v1","kind":"CD","metadata":{"annotations":{"gotemplating.fn.crossplane.io/composition-resource-name":"cool-cd"},"name":{{ costCenter | quote }}}}`
+		return &InlineSource{Template: t, Entries: []TemplateEntry{{Name: "custom", Template: t}}}, nil
+	}
+
+	type args struct {
+		opts []Option
+		tmpl string
+	}
+	type want struct {
+		rsp *fnv1.RunFunctionResponse
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"CustomSourceAndFunctions": {
+			reason: "The Renderer should use custom template sources and functions.",
+			args: args{
+				opts: []Option{
+					WithTemplateSource(custom, customSource),
+					WithFunctions(template.FuncMap{"costCenter": func() string { return "cc-42" }}),
+				},
+			},
+			want: want{
+				rsp: &fnv1.RunFunctionResponse{
+					Meta: &fnv1.ResponseMeta{Ttl: durationpb.New(response.DefaultTTL)},
+					Desired: &fnv1.State{
+						Composite: &fnv1.Resource{
+							Resource: resource.MustStructJSON(`{}`),
+						},
+						Resources: map[string]*fnv1.Resource{
+							"cool-cd": {
+								Resource: resource.MustStructJSON(`{"apiVersion":"example.org/v1","kind":"CD","metadata":{"annotations":{},"name":"cc-42"}}`),
+							},
+						},
+					},
+				},
+			},
+		},
+		"CustomMetaKind": {
+			reason: "The Renderer should pass resources of custom meta kinds to their handlers.",
+			args: args{
+				opts: []Option{
+					WithMetaKind("Event", func(_ *fnv1.RunFunctionRequest, rsp *fnv1.RunFunctionResponse, obj *unstructured.Unstructured) error {
+						msg, _, _ := unstructured.NestedString(obj.Object, "message")
+						response.Normal(rsp, msg)
+						return nil
+					}),
+				},
+				tmpl: `{"apiVersion":"meta.gotemplating.fn.crossplane.io/v1alpha1","kind":"Event","message":"hello"}`,
+			},
+			want: want{
+				rsp: &fnv1.RunFunctionResponse{
+					Meta: &fnv1.ResponseMeta{Ttl: durationpb.New(response.DefaultTTL)},
+					Results: []*fnv1.Result{
+						{
+							Severity: fnv1.Severity_SEVERITY_NORMAL,
+							Message:  "hello",
+							Target:   fnv1.Target_TARGET_COMPOSITE.Enum(),
+						},
+					},
+					Desired: &fnv1.State{
+						Composite: &fnv1.Resource{
+							Resource: resource.MustStructJSON(`{}`),
+						},
+					},
+				},
+			},
+		},
+		"CustomMetaKindError": {
+			reason: "The Renderer should return a fatal result if a meta kind handler fails.",
+			args: args{
+				opts: []Option{
+					WithMetaKind("Broken", func(_ *fnv1.RunFunctionRequest, _ *fnv1.RunFunctionResponse, _ *unstructured.Unstructured) error {
+						return errors.New("boom")
+					}),
+				},
+				tmpl: `{"apiVersion":"meta.gotemplating.fn.crossplane.io/v1alpha1","kind":"Broken"}`,
+			},
+			want: want{
+				rsp: &fnv1.RunFunctionResponse{
+					Meta: &fnv1.ResponseMeta{Ttl: durationpb.New(response.DefaultTTL)},
+					Results: []*fnv1.Result{
+						{
+							Severity: fnv1.Severity_SEVERITY_FATAL,
+							Message:  `cannot handle kind "Broken": boom`,
+							Target:   fnv1.Target_TARGET_COMPOSITE.Enum(),
+						},
+					},
+				},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			in := &v1beta1.GoTemplate{Source: custom}
+			if tc.args.tmpl != "" {
+				in = &v1beta1.GoTemplate{Source: v1beta1.InlineSource, Inline: &v1beta1.TemplateSourceInline{Template: tc.args.tmpl}}
+			}
+			req := &fnv1.RunFunctionRequest{
+				Input: resource.MustStructObject(in),
+				Observed: &fnv1.State{
+					Composite: &fnv1.Resource{
+						Resource: resource.MustStructJSON(xr),
+					},
+				},
+			}
+			rsp, err := New(tc.args.opts...).Render(context.Background(), req)
+			if err != nil {
+				t.Fatalf("Render(...): %v", err)
+			}
+			if diff := cmp.Diff(tc.want.rsp, rsp, protocmp.Transform()); diff != "" {
+				t.Errorf("%s\nRender(...): -want rsp, +got rsp:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package render
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/crossplane-contrib/function-go-templating/input/v1beta1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/yaml"
+
+	"github.com/crossplane/function-sdk-go/errors"
+)
+
+// documentSource is the template file a rendered document was rendered from,
+// and the index of the document in that file.
+type documentSource struct {
+	// Document is the index of the document in the rendered manifests.
+	Document int
+	File     string
+	Index    int
+}
+
+// A renderedDocument is a document of the rendered manifests, in the order the
+// YAML decoder reads them.
+type renderedDocument struct {
+	Text string
+	// File is the template file the document was rendered from, if known.
+	File string
+	// Index is the index of the document in its file, or in the rendered
+	// manifests if the file is unknown. Empty documents have no index.
+	Index int
+}
+
+// documentsOf splits the supplied rendered manifests of a template file into
+// documents, in the order the YAML decoder reads them.
+func documentsOf(data, file string) []renderedDocument {
+	var docs []renderedDocument
+	r := yaml.NewYAMLReader(bufio.NewReader(strings.NewReader(data)))
+	for {
+		doc, err := r.Read()
+		if err != nil {
+			// The decoder reports errors, we only need the documents.
+			if !errors.Is(err, io.EOF) {
+				docs = append(docs, renderedDocument{File: file})
+			}
+			return docs
+		}
+		docs = append(docs, renderedDocument{Text: string(doc), File: file})
+	}
+}
+
+// numberDocuments sets the index of each document in its file. Documents are
+// numbered before they are decoded, so that a document that can't be decoded
+// doesn't change the index of the following documents.
+func numberDocuments(docs []renderedDocument) {
+	next := make(map[string]int)
+	for i := range docs {
+		if isEmptyDocument(docs[i].Text) {
+			continue
+		}
+		docs[i].Index = next[docs[i].File]
+		next[docs[i].File]++
+	}
+}
+
+// isEmptyDocument reports whether the supplied document only contains blank
+// lines and comments, which the YAML decoder skips.
+func isEmptyDocument(doc string) bool {
+	for _, line := range strings.Split(doc, "\n") {
+		if l := strings.TrimSpace(line); l != "" && !strings.HasPrefix(l, "#") {
+			return false
+		}
+	}
+
+	return true
+}
+
+// A resourceNamer derives composition resource names for rendered resources
+// that don't have the composition-resource-name annotation.
+type resourceNamer struct {
+	policy v1beta1.ResourceNamingPolicy
+	tmpl   *template.Template
+	names  map[string]bool
+	// annotated are the names set with the composition-resource-name
+	// annotation, which derived names must not collide with.
+	annotated map[string]bool
+}
+
+// newResourceNamer returns a resourceNamer for the supplied policy. It
+// returns nil if no policy is configured.
+func newResourceNamer(in *v1beta1.ResourceNaming, delims *v1beta1.Delims, funcs ...template.FuncMap) (*resourceNamer, error) {
+	if in == nil {
+		return nil, nil
+	}
+
+	n := &resourceNamer{policy: in.Policy, names: make(map[string]bool), annotated: make(map[string]bool)}
+	switch in.Policy {
+	case v1beta1.KindAndNamePolicy, v1beta1.FileAndIndexPolicy:
+	case v1beta1.TemplatePolicy:
+		if in.Template == "" {
+			return nil, errors.New("resourceNaming.template is required for the Template policy")
+		}
+		tmpl, err := GetNewTemplateWithFunctionMaps(delims, funcs...).Option("missingkey=error").Parse(in.Template)
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot parse resourceNaming.template")
+		}
+		n.tmpl = tmpl
+	default:
+		return nil, errors.Errorf("invalid resourceNaming.policy %q: must be one of KindAndName, Template or FileAndIndex", in.Policy)
+	}
+
+	return n, nil
+}
+
+// UsesFiles reports whether derived names depend on the template file of a
+// resource.
+func (n *resourceNamer) UsesFiles() bool {
+	return n != nil && (n.policy == v1beta1.FileAndIndexPolicy || n.policy == v1beta1.TemplatePolicy)
+}
+
+// Reserve marks a name that is set with the composition-resource-name
+// annotation, so that no name is derived that collides with it.
+func (n *resourceNamer) Reserve(name string) {
+	n.annotated[name] = true
+}
+
+// Name returns the composition resource name of the supplied resource. It
+// returns an error if the name is empty, was already derived for another
+// resource or is set with the composition-resource-name annotation of another
+// resource.
+func (n *resourceNamer) Name(obj *unstructured.Unstructured, src documentSource) (string, error) {
+	var name string
+	switch n.policy {
+	case v1beta1.KindAndNamePolicy:
+		if obj.GetName() == "" {
+			return "", errors.Errorf("cannot derive name of %q resource without metadata.name", obj.GetKind())
+		}
+		name = strings.ToLower(obj.GetKind()) + "-" + obj.GetName()
+	case v1beta1.FileAndIndexPolicy:
+		file := "template"
+		if src.File != "" {
+			base := filepath.Base(src.File)
+			file = strings.TrimSuffix(base, filepath.Ext(base))
+		}
+		name = file + "-" + strconv.Itoa(src.Index)
+	case v1beta1.TemplatePolicy:
+		buf := &bytes.Buffer{}
+		if err := n.tmpl.Execute(buf, map[string]any{"resource": obj.Object, "file": src.File, "index": src.Index}); err != nil {
+			return "", errors.Wrap(err, "cannot execute resourceNaming.template")
+		}
+		name = strings.TrimSpace(buf.String())
+		if name == "" {
+			return "", errors.Errorf("resourceNaming.template rendered an empty name for %q resource %q", obj.GetKind(), obj.GetName())
+		}
+	}
+
+	if n.names[name] {
+		return "", errors.Errorf("derived composition resource name %q is not unique", name)
+	}
+	if n.annotated[name] {
+		return "", errors.Errorf("derived composition resource name %q is already set by the %q annotation of another resource", name, annotationKeyCompositionResourceName)
+	}
+	n.names[name] = true
+
+	return name, nil
+}
@@ -0,0 +1,151 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/crossplane-contrib/function-go-templating/input/v1beta1"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func Test_numberDocuments(t *testing.T) {
+	docs := documentsOf("kind: A\n---\n# only a comment\n---\nkind: [broken\n---\nkind: B\n", "templates/a.yaml")
+	docs = append(docs, documentsOf("kind: C\n", "templates/b.yaml")...)
+	numberDocuments(docs)
+
+	want := []renderedDocument{
+		{Text: "kind: A\n", File: "templates/a.yaml", Index: 0},
+		{Text: "# only a comment\n", File: "templates/a.yaml"},
+		{Text: "kind: [broken\n", File: "templates/a.yaml", Index: 1},
+		{Text: "kind: B\n", File: "templates/a.yaml", Index: 2},
+		{Text: "kind: C\n", File: "templates/b.yaml", Index: 0},
+	}
+	if diff := cmp.Diff(want, docs); diff != "" {
+		t.Errorf("numberDocuments(...): -want, +got:\n%s", diff)
+	}
+}
+
+func Test_resourceNamer(t *testing.T) {
+	bucket := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "example.org/v1",
+		"kind":       "Bucket",
+		"metadata":   map[string]any{"name": "logs"},
+	}}
+	unnamed := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "example.org/v1",
+		"kind":       "Bucket",
+	}}
+
+	type args struct {
+		naming   *v1beta1.ResourceNaming
+		reserved []string
+		objs     []*unstructured.Unstructured
+		src      documentSource
+	}
+	type want struct {
+		names []string
+		err   error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"KindAndName": {
+			reason: "Should derive the name from the lower case kind and the name",
+			args: args{
+				naming: &v1beta1.ResourceNaming{Policy: v1beta1.KindAndNamePolicy},
+				objs:   []*unstructured.Unstructured{bucket},
+			},
+			want: want{names: []string{"bucket-logs"}},
+		},
+		"KindAndNameWithoutName": {
+			reason: "Should return an error if the resource has no name",
+			args: args{
+				naming: &v1beta1.ResourceNaming{Policy: v1beta1.KindAndNamePolicy},
+				objs:   []*unstructured.Unstructured{unnamed},
+			},
+			want: want{names: []string{}, err: cmpopts.AnyError},
+		},
+		"FileAndIndex": {
+			reason: "Should derive the name from the file name without extension and the index",
+			args: args{
+				naming: &v1beta1.ResourceNaming{Policy: v1beta1.FileAndIndexPolicy},
+				objs:   []*unstructured.Unstructured{bucket},
+				src:    documentSource{File: "templates/buckets.yaml.tmpl", Index: 2},
+			},
+			want: want{names: []string{"buckets.yaml-2"}},
+		},
+		"FileAndIndexInline": {
+			reason: "Should use template as the file name of inline templates",
+			args: args{
+				naming: &v1beta1.ResourceNaming{Policy: v1beta1.FileAndIndexPolicy},
+				objs:   []*unstructured.Unstructured{bucket},
+				src:    documentSource{Index: 1},
+			},
+			want: want{names: []string{"template-1"}},
+		},
+		"Template": {
+			reason: "Should derive the name by rendering the template",
+			args: args{
+				naming: &v1beta1.ResourceNaming{Policy: v1beta1.TemplatePolicy, Template: `{{ .resource.kind | lower }}-{{ .index }}`},
+				objs:   []*unstructured.Unstructured{bucket},
+				src:    documentSource{Index: 3},
+			},
+			want: want{names: []string{"bucket-3"}},
+		},
+		"TemplateEmptyName": {
+			reason: "Should return an error if the template renders an empty name",
+			args: args{
+				naming: &v1beta1.ResourceNaming{Policy: v1beta1.TemplatePolicy, Template: `{{ "" }}`},
+				objs:   []*unstructured.Unstructured{bucket},
+			},
+			want: want{names: []string{}, err: cmpopts.AnyError},
+		},
+		"Annotated": {
+			reason: "Should return an error if a derived name is set with the resource name annotation",
+			args: args{
+				naming:   &v1beta1.ResourceNaming{Policy: v1beta1.KindAndNamePolicy},
+				reserved: []string{"bucket-logs"},
+				objs:     []*unstructured.Unstructured{bucket},
+			},
+			want: want{names: []string{}, err: cmpopts.AnyError},
+		},
+		"NotUnique": {
+			reason: "Should return an error if a name was already derived",
+			args: args{
+				naming: &v1beta1.ResourceNaming{Policy: v1beta1.KindAndNamePolicy},
+				objs:   []*unstructured.Unstructured{bucket, bucket},
+			},
+			want: want{names: []string{"bucket-logs"}, err: cmpopts.AnyError},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			n, err := newResourceNamer(tc.args.naming, nil)
+			if err != nil {
+				t.Fatalf("newResourceNamer(...): %v", err)
+			}
+			for _, name := range tc.args.reserved {
+				n.Reserve(name)
+			}
+			names := []string{}
+			for _, obj := range tc.args.objs {
+				var name string
+				name, err = n.Name(obj, tc.args.src)
+				if err != nil {
+					break
+				}
+				names = append(names, name)
+			}
+			if diff := cmp.Diff(tc.want.names, names); diff != "" {
+				t.Errorf("%s\nName(...): -want names, +got names:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("%s\nName(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,283 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"math"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/crossplane-contrib/function-go-templating/input/v1beta1"
+	"google.golang.org/protobuf/encoding/protojson"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/json"
+	"k8s.io/apimachinery/pkg/util/yaml"
+
+	"github.com/crossplane/function-sdk-go/errors"
+	fnv1 "github.com/crossplane/function-sdk-go/proto/v1"
+	"github.com/crossplane/function-sdk-go/request"
+)
+
+// The schema validation catches mistakes the API server would silently
+// ignore, like misspelled fields that are pruned. It checks for unknown
+// fields, type mismatches and missing required fields. It doesn't implement
+// other OpenAPI validations like formats, patterns or CEL rules.
+
+// schemas are the OpenAPI schemas of resource kinds.
+type schemas map[schema.GroupVersionKind]*extv1.JSONSchemaProps
+
+// loadCRDSchemas returns the schemas of all CRDs in the YAML files under dir.
+// Documents that are not CRDs are ignored.
+func loadCRDSchemas(fsys fs.FS, dir string) (schemas, error) {
+	s := make(schemas)
+	err := fs.WalkDir(fsys, dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		// Skip hidden files and directories.
+		if d.Name()[0] == dotCharacter && path != dir {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		data, err := fs.ReadFile(fsys, path)
+		if err != nil {
+			return err
+		}
+		decoder := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), 1024)
+		for {
+			crd := &extv1.CustomResourceDefinition{}
+			if err := decoder.Decode(crd); err != nil {
+				if errors.Is(err, io.EOF) {
+					return nil
+				}
+				return errors.Wrapf(err, "cannot decode %s", path)
+			}
+			if crd.Kind != "CustomResourceDefinition" {
+				continue
+			}
+			for _, v := range crd.Spec.Versions {
+				if v.Schema == nil || v.Schema.OpenAPIV3Schema == nil {
+					continue
+				}
+				s[schema.GroupVersionKind{Group: crd.Spec.Group, Version: v.Name, Kind: crd.Spec.Names.Kind}] = v.Schema.OpenAPIV3Schema
+			}
+		}
+	})
+
+	return s, errors.Wrapf(err, "cannot load CRDs from %s", dir)
+}
+
+// A schemaValidator validates composed resources against the schemas of
+// their kinds.
+type schemaValidator struct {
+	req          *fnv1.RunFunctionRequest
+	schemas      schemas
+	require      bool
+	requirements *fnv1.Requirements
+}
+
+// newSchemaValidator returns a schemaValidator for the supplied input. It
+// returns nil if schema validation is not configured. Schema requirements are
+// added to the supplied requirements.
+func newSchemaValidator(fsys fs.FS, req *fnv1.RunFunctionRequest, in *v1beta1.SchemaValidation, requirements *fnv1.Requirements) (*schemaValidator, error) {
+	if in == nil {
+		return nil, nil
+	}
+
+	v := &schemaValidator{req: req, schemas: make(schemas), require: in.RequireSchemas, requirements: requirements}
+	if in.DirPath != "" {
+		s, err := loadCRDSchemas(fsys, in.DirPath)
+		if err != nil {
+			return nil, err
+		}
+		v.schemas = s
+	}
+
+	return v, nil
+}
+
+// Validate returns the problems of the supplied resource. Resources of kinds
+// without a schema are not validated.
+func (v *schemaValidator) Validate(obj map[string]any, gvk schema.GroupVersionKind) ([]string, error) {
+	if s, ok := v.schemas[gvk]; ok {
+		return validateResource(obj, s), nil
+	}
+	if !v.require {
+		return nil, nil
+	}
+
+	// Crossplane only resolves schemas that are required in every response.
+	name := requiredSchemaName(gvk)
+	if v.requirements.Schemas == nil {
+		v.requirements.Schemas = make(map[string]*fnv1.SchemaSelector)
+	}
+	v.requirements.Schemas[name] = &fnv1.SchemaSelector{ApiVersion: gvk.GroupVersion().String(), Kind: gvk.Kind}
+
+	s, _, err := getRequiredSchema(v.req, gvk)
+	if err != nil || s == nil {
+		return nil, err
+	}
+	v.schemas[gvk] = s
+
+	return validateResource(obj, s), nil
+}
+
+// requiredSchemaName returns the name of the schema requirement of the
+// supplied kind.
+func requiredSchemaName(gvk schema.GroupVersionKind) string {
+	return "schema/" + gvk.GroupVersion().String() + "/" + gvk.Kind
+}
+
+// getRequiredSchema returns the schema of the supplied kind that Crossplane
+// resolved. It returns false if the schema was not yet requested.
+func getRequiredSchema(req *fnv1.RunFunctionRequest, gvk schema.GroupVersionKind) (*extv1.JSONSchemaProps, bool, error) {
+	s, ok := request.GetRequiredSchema(req, requiredSchemaName(gvk))
+	if !ok || s == nil {
+		return nil, ok, nil
+	}
+
+	j, err := protojson.Marshal(s)
+	if err != nil {
+		return nil, true, errors.Wrapf(err, "cannot marshal schema of %s", gvk)
+	}
+	props := &extv1.JSONSchemaProps{}
+	if err := json.Unmarshal(j, props); err != nil {
+		return nil, true, errors.Wrapf(err, "cannot unmarshal schema of %s", gvk)
+	}
+
+	return props, true, nil
+}
+
+// validateResource validates the supplied resource against s. It returns a
+// sorted list of problems, each prefixed by the path of the invalid field.
+func validateResource(obj map[string]any, s *extv1.JSONSchemaProps) []string {
+	problems := validateObject("", obj, s, true)
+	slices.Sort(problems)
+
+	return problems
+}
+
+func validateValue(path string, v any, s *extv1.JSONSchemaProps) []string {
+	// The API server prunes null values of fields that are not nullable.
+	if v == nil || s == nil {
+		return nil
+	}
+	if s.XIntOrString {
+		if t := typeOf(v); t != "integer" && t != "string" {
+			return []string{fmt.Sprintf("%s: expected integer or string, got %s", path, t)}
+		}
+		return nil
+	}
+
+	t := typeOf(v)
+	if s.Type != "" && t != s.Type && (s.Type != "number" || t != "integer") {
+		return []string{fmt.Sprintf("%s: expected %s, got %s", path, s.Type, t)}
+	}
+
+	switch val := v.(type) {
+	case map[string]any:
+		return validateObject(path, val, s, s.XEmbeddedResource)
+	case []any:
+		if s.Items == nil || s.Items.Schema == nil {
+			return nil
+		}
+		var problems []string
+		for i, item := range val {
+			problems = append(problems, validateValue(fmt.Sprintf("%s[%d]", path, i), item, s.Items.Schema)...)
+		}
+		return problems
+	}
+
+	return nil
+}
+
+// validateObject validates the fields of an object. The apiVersion, kind and
+// metadata of embedded resources are always known, and their metadata is not
+// validated.
+func validateObject(path string, obj map[string]any, s *extv1.JSONSchemaProps, embedded bool) []string {
+	var problems []string
+	for _, r := range s.Required {
+		if _, ok := obj[r]; !ok {
+			problems = append(problems, fmt.Sprintf("%s: required field is missing", fieldPath(path, r)))
+		}
+	}
+
+	for k, v := range obj {
+		p := fieldPath(path, k)
+		if embedded && (k == "apiVersion" || k == "kind" || k == "metadata") {
+			if k != "metadata" {
+				if ps, ok := s.Properties[k]; ok {
+					problems = append(problems, validateValue(p, v, &ps)...)
+				}
+			}
+			continue
+		}
+		if ps, ok := s.Properties[k]; ok {
+			problems = append(problems, validateValue(p, v, &ps)...)
+			continue
+		}
+		if s.AdditionalProperties != nil {
+			if s.AdditionalProperties.Schema != nil {
+				problems = append(problems, validateValue(p, v, s.AdditionalProperties.Schema)...)
+			}
+			if s.AdditionalProperties.Schema != nil || s.AdditionalProperties.Allows {
+				continue
+			}
+		}
+		if s.XPreserveUnknownFields != nil && *s.XPreserveUnknownFields {
+			continue
+		}
+		problems = append(problems, fmt.Sprintf("%s: unknown field", p))
+	}
+
+	return problems
+}
+
+// typeOf returns the OpenAPI type of the supplied value.
+func typeOf(v any) string {
+	switch val := v.(type) {
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case int, int32, int64:
+		return "integer"
+	case float32:
+		if math.Trunc(float64(val)) == float64(val) {
+			return "integer"
+		}
+		return "number"
+	case float64:
+		if math.Trunc(val) == val {
+			return "integer"
+		}
+		return "number"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
+
+func fieldPath(path, field string) string {
+	if path == "" {
+		return field
+	}
+	if strings.ContainsAny(field, ".[]") {
+		return fmt.Sprintf("%s[%s]", path, field)
+	}
+
+	return path + "." + field
+}
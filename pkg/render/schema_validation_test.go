@@ -0,0 +1,131 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/ptr"
+)
+
+func Test_loadCRDSchemas(t *testing.T) {
+	s, err := loadCRDSchemas(testdataFS, "testdata/crds")
+	if err != nil {
+		t.Fatalf("loadCRDSchemas(...): %v", err)
+	}
+	want := []schema.GroupVersionKind{{Group: "example.org", Version: "v1", Kind: "Bucket"}}
+	got := make([]schema.GroupVersionKind, 0, len(s))
+	for gvk := range s {
+		got = append(got, gvk)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("loadCRDSchemas(...): -want, +got:\n%s", diff)
+	}
+
+	if _, err := loadCRDSchemas(testdataFS, wrongPath); err == nil {
+		t.Errorf("loadCRDSchemas(...): want error for missing directory")
+	}
+}
+
+func Test_validateResource(t *testing.T) {
+	s := &extv1.JSONSchemaProps{
+		Type: "object",
+		Properties: map[string]extv1.JSONSchemaProps{
+			"spec": {
+				Type:     "object",
+				Required: []string{"region"},
+				Properties: map[string]extv1.JSONSchemaProps{
+					"region":   {Type: "string"},
+					"zone":     {Type: "string"},
+					"replicas": {Type: "integer"},
+					"ratio":    {Type: "number"},
+					"port":     {XIntOrString: true},
+					"ports": {
+						Type:  "array",
+						Items: &extv1.JSONSchemaPropsOrArray{Schema: &extv1.JSONSchemaProps{Type: "integer"}},
+					},
+					"tags": {
+						Type:                 "object",
+						AdditionalProperties: &extv1.JSONSchemaPropsOrBool{Schema: &extv1.JSONSchemaProps{Type: "string"}},
+					},
+					"values": {
+						Type:                   "object",
+						XPreserveUnknownFields: ptr.To(true),
+					},
+					"template": {
+						Type:              "object",
+						XEmbeddedResource: true,
+						Properties: map[string]extv1.JSONSchemaProps{
+							"spec": {Type: "object"},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	cases := map[string]struct {
+		reason string
+		obj    map[string]any
+		want   []string
+	}{
+		"Valid": {
+			reason: "Should return no problems for a valid resource",
+			obj: map[string]any{
+				"apiVersion": "example.org/v1",
+				"kind":       "Bucket",
+				"metadata":   map[string]any{"name": "cool-bucket"},
+				"spec": map[string]any{
+					"region":   "eu-west-1",
+					"replicas": int64(2),
+					"ratio":    int64(1),
+					"port":     "http",
+					"ports":    []any{int64(80), float64(443)},
+					"tags":     map[string]any{"team": "platform"},
+					"values":   map[string]any{"any": map[string]any{"thing": true}},
+					"template": map[string]any{"apiVersion": "v1", "kind": "Pod", "metadata": map[string]any{}, "spec": map[string]any{}},
+					"zone":     nil,
+				},
+			},
+		},
+		"Invalid": {
+			reason: "Should return unknown fields, type mismatches and missing required fields",
+			obj: map[string]any{
+				"apiVersion": "example.org/v1",
+				"kind":       "Bucket",
+				"spec": map[string]any{
+					"regoin":   "eu-west-1",
+					"replicas": "2",
+					"ratio":    true,
+					"port":     1.5,
+					"ports":    []any{int64(80), "https"},
+					"tags":     map[string]any{"count": int64(1)},
+					"template": map[string]any{"status": map[string]any{}},
+				},
+			},
+			want: []string{
+				"port: expected integer or string, got number",
+				"ports[1]: expected integer, got string",
+				"ratio: expected number, got boolean",
+				"region: required field is missing",
+				"regoin: unknown field",
+				"replicas: expected integer, got string",
+				"tags.count: expected string, got integer",
+				"template.status: unknown field",
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := validateResource(tc.obj, s)
+			for i := range tc.want {
+				tc.want[i] = "spec." + tc.want[i]
+			}
+			if diff := cmp.Diff(tc.want, got, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("%s\nvalidateResource(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
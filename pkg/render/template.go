@@ -1,6 +1,7 @@
-package main
+package render
 
 import (
+	"fmt"
 	"io/fs"
 	"path/filepath"
 	"strings"
@@ -17,8 +18,24 @@ const dotCharacter = 46
 type TemplateGetter interface {
 	// GetTemplates returns the templates from the datasource
 	GetTemplates() string
+	// GetTemplateEntries returns the templates from the datasource as
+	// separate entries, e.g. one per file.
+	GetTemplateEntries() []TemplateEntry
 }
 
+// A TemplateEntry is a template that can be executed on its own, like an entry
+// of inline.templates or a file.
+type TemplateEntry struct {
+	// Name identifies the entry, e.g. templates[1] or the path of a file.
+	Name string
+	// File is the path of the file the entry was read from, if any.
+	File     string
+	Template string
+}
+
+// A TemplateSourceFunc returns the TemplateGetter of a template source.
+type TemplateSourceFunc func(fsys fs.FS, ctx *structpb.Struct, in *v1beta1.GoTemplate) (TemplateGetter, error)
+
 // NewTemplateSourceGetter returns a TemplateGetter based on the cd source.
 func NewTemplateSourceGetter(fsys fs.FS, ctx *structpb.Struct, in *v1beta1.GoTemplate) (TemplateGetter, error) {
 	switch in.Source {
@@ -38,12 +55,14 @@ func NewTemplateSourceGetter(fsys fs.FS, ctx *structpb.Struct, in *v1beta1.GoTem
 // InlineSource is a datasource that reads a template from the composition.
 type InlineSource struct {
 	Template string
+	Entries  []TemplateEntry
 }
 
 // FileSource is a datasource that reads a template from a folder.
 type FileSource struct {
 	FolderPath string
 	Template   string
+	Entries    []TemplateEntry
 }
 
 // EnvironmentSource is a datasource that reads a template from the environment.
@@ -57,19 +76,31 @@ func (is *InlineSource) GetTemplates() string {
 	return is.Template
 }
 
+// GetTemplateEntries returns the inline template, or each of the inline
+// templates.
+func (is *InlineSource) GetTemplateEntries() []TemplateEntry {
+	return is.Entries
+}
+
 func newInlineSource(in *v1beta1.GoTemplate) (*InlineSource, error) {
 	if in.Inline == nil || (in.Inline.Template == "" && len(in.Inline.Templates) == 0) {
 		return nil, errors.New("inline.template or inline.templates should be provided")
 	}
 
 	template := strings.Join(in.Inline.Templates, "\n---\n")
+	entries := make([]TemplateEntry, len(in.Inline.Templates))
+	for i, t := range in.Inline.Templates {
+		entries[i] = TemplateEntry{Name: fmt.Sprintf("templates[%d]", i), Template: t}
+	}
 
 	if in.Inline.Template != "" {
 		template = in.Inline.Template
+		entries = []TemplateEntry{{Name: "template", Template: template}}
 	}
 
 	return &InlineSource{
 		Template: template,
+		Entries:  entries,
 	}, nil
 }
 
@@ -78,6 +109,11 @@ func (fs *FileSource) GetTemplates() string {
 	return fs.Template
 }
 
+// GetTemplateEntries returns each template file in the folder.
+func (fs *FileSource) GetTemplateEntries() []TemplateEntry {
+	return fs.Entries
+}
+
 func newFileSource(fsys fs.FS, in *v1beta1.GoTemplate) (*FileSource, error) {
 	if in.FileSystem == nil || in.FileSystem.DirPath == "" {
 		return nil, errors.New("fileSystem.dirPath should be provided")
@@ -85,14 +121,21 @@ func newFileSource(fsys fs.FS, in *v1beta1.GoTemplate) (*FileSource, error) {
 
 	d := in.FileSystem.DirPath
 
-	tmpl, err := readTemplates(fsys, d)
+	entries, err := readTemplates(fsys, d)
 	if err != nil {
 		return nil, errors.Errorf("cannot read tmpl from the folder %s: %s", *in.FileSystem, err)
 	}
 
+	tmpl := ""
+	for _, e := range entries {
+		tmpl += e.Template
+		tmpl += "\n---\n"
+	}
+
 	return &FileSource{
 		FolderPath: in.FileSystem.DirPath,
 		Template:   tmpl,
+		Entries:    entries,
 	}, nil
 }
 
@@ -100,6 +143,11 @@ func (es *EnvironmentSource) GetTemplates() string {
 	return es.Template
 }
 
+// GetTemplateEntries returns the template in the environment.
+func (es *EnvironmentSource) GetTemplateEntries() []TemplateEntry {
+	return []TemplateEntry{{Name: es.Key, Template: es.Template}}
+}
+
 func newEnvironmentSource(ctx *structpb.Struct, in *v1beta1.GoTemplate) (*EnvironmentSource, error) {
 	if in.Environment == nil || in.Environment.Key == "" {
 		return nil, errors.New("environment.key should be provided")
@@ -118,12 +166,13 @@ func newEnvironmentSource(ctx *structpb.Struct, in *v1beta1.GoTemplate) (*Enviro
 		return nil, errors.Errorf("cannot read tmpl from the environment: key: %s value is not a string", in.Environment.Key)
 	}
 	return &EnvironmentSource{
+		Key:      in.Environment.Key,
 		Template: t,
 	}, nil
 }
 
-func readTemplates(fsys fs.FS, dir string) (string, error) {
-	tmpl := ""
+func readTemplates(fsys fs.FS, dir string) ([]TemplateEntry, error) {
+	var entries []TemplateEntry
 
 	if err := fs.WalkDir(fsys, dir, func(path string, dirEntry fs.DirEntry, e error) error {
 		if e != nil {
@@ -150,13 +199,12 @@ func readTemplates(fsys fs.FS, dir string) (string, error) {
 			return err
 		}
 
-		tmpl += string(data)
-		tmpl += "\n---\n"
+		entries = append(entries, TemplateEntry{Name: path, File: path, Template: string(data)})
 
 		return nil
 	}); err != nil {
-		return "", err
+		return nil, err
 	}
 
-	return tmpl, nil
+	return entries, nil
 }
@@ -0,0 +1,227 @@
+package render
+
+import (
+	"context"
+	"encoding/json"
+	"io/fs"
+	"maps"
+	fspath "path"
+	"regexp"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+
+	"github.com/crossplane/function-sdk-go/errors"
+)
+
+const (
+	// wasmFunctionPrefix is the prefix of the exports of a WASM module that
+	// are template functions.
+	wasmFunctionPrefix = "tmpl_"
+
+	// DefaultWASMTimeout is the default time a call of a WASM function may
+	// take.
+	DefaultWASMTimeout = time.Second
+
+	// DefaultWASMMemoryLimitPages is the default memory limit of a WASM
+	// module, in 64KiB pages. The default is 16MiB.
+	DefaultWASMMemoryLimitPages = 256
+)
+
+// templateFunctionName matches valid names of template functions.
+var templateFunctionName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// WASMConfig configures the sandbox of WASM modules.
+type WASMConfig struct {
+	// Timeout of a call of a WASM function.
+	Timeout time.Duration
+	// MemoryLimitPages is the memory limit of a module, in 64KiB pages.
+	MemoryLimitPages uint32
+}
+
+// LoadWASMFunctions registers the template functions of the WASM modules in
+// the supplied directory, one module per .wasm file, named after the file. It
+// returns the names of the modules, or an error if a module with the same name
+// is already registered.
+//
+// A module must export its memory as "memory", and an allocation function
+// "alloc(size i32) i32" that returns a pointer to size bytes. Each exported
+// function "tmpl_<name>(ptr i32, len i32) i64" is available to templates as
+// <name>. It's passed the JSON array of the template arguments at ptr and
+// len, and returns the pointer to its JSON output in the upper 32 bits and
+// its length in the lower 32 bits. The output is {"result": <value>}, or
+// {"error": "<message>"} if the function failed.
+//
+// Modules are sandboxed. They can't import host functions, so they have no
+// access to the file system, the network, the environment or the clock. Each
+// call runs in a new instance of the module, limited by the timeout and memory
+// limit of the supplied config.
+func LoadWASMFunctions(ctx context.Context, fsys fs.FS, dir string, cfg WASMConfig) ([]string, error) {
+	modules, err := loadWASMModules(ctx, fsys, dir, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if err := registerModules(modules); err != nil {
+		return nil, errors.Wrapf(err, "cannot register WASM modules from %s", dir)
+	}
+
+	return slices.Sorted(maps.Keys(modules)), nil
+}
+
+// loadWASMModules returns the template functions of the WASM modules in the
+// supplied directory, by module name.
+func loadWASMModules(ctx context.Context, fsys fs.FS, dir string, cfg WASMConfig) (map[string]template.FuncMap, error) {
+	if cfg.Timeout == 0 {
+		cfg.Timeout = DefaultWASMTimeout
+	}
+	if cfg.MemoryLimitPages == 0 {
+		cfg.MemoryLimitPages = DefaultWASMMemoryLimitPages
+	}
+
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot read WASM modules from %s", dir)
+	}
+
+	// The runtime is shared by all modules for the lifetime of the process.
+	r := wazero.NewRuntimeWithConfig(ctx, wazero.NewRuntimeConfig().
+		WithMemoryLimitPages(cfg.MemoryLimitPages).
+		WithCloseOnContextDone(true))
+
+	modules := make(map[string]template.FuncMap)
+	for _, e := range entries {
+		if e.IsDir() || fspath.Ext(e.Name()) != ".wasm" {
+			continue
+		}
+		name := strings.TrimSuffix(e.Name(), ".wasm")
+		// Paths of an fs.FS are slash-separated on all platforms.
+		bin, err := fs.ReadFile(fsys, fspath.Join(dir, e.Name()))
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot read WASM module %s", name)
+		}
+		m, err := compileWASMModule(ctx, r, bin, cfg.Timeout)
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot load WASM module %s", name)
+		}
+		modules[name] = m.functions()
+	}
+
+	return modules, nil
+}
+
+// A wasmModule is a compiled WASM module with template functions.
+type wasmModule struct {
+	runtime wazero.Runtime
+	module  wazero.CompiledModule
+	timeout time.Duration
+	exports []string
+}
+
+// compileWASMModule compiles the supplied module and validates its exports.
+func compileWASMModule(ctx context.Context, r wazero.Runtime, bin []byte, timeout time.Duration) (*wasmModule, error) {
+	cm, err := r.CompileModule(ctx, bin)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot compile module")
+	}
+	if imports := cm.ImportedFunctions(); len(imports) > 0 {
+		mod, name, _ := imports[0].Import()
+		return nil, errors.Errorf("imported function %s.%s is not allowed", mod, name)
+	}
+	if _, ok := cm.ExportedMemories()["memory"]; !ok {
+		return nil, errors.New("module must export its memory as \"memory\"")
+	}
+
+	exported := cm.ExportedFunctions()
+	alloc, ok := exported["alloc"]
+	if !ok || !hasSignature(alloc, []api.ValueType{api.ValueTypeI32}, []api.ValueType{api.ValueTypeI32}) {
+		return nil, errors.New("module must export an \"alloc(size i32) i32\" function")
+	}
+
+	m := &wasmModule{runtime: r, module: cm, timeout: timeout}
+	for export, def := range exported {
+		name, ok := strings.CutPrefix(export, wasmFunctionPrefix)
+		if !ok {
+			continue
+		}
+		if !templateFunctionName.MatchString(name) {
+			return nil, errors.Errorf("invalid template function name %q", name)
+		}
+		if !hasSignature(def, []api.ValueType{api.ValueTypeI32, api.ValueTypeI32}, []api.ValueType{api.ValueTypeI64}) {
+			return nil, errors.Errorf("function %s must have the signature (ptr i32, len i32) i64", export)
+		}
+		m.exports = append(m.exports, export)
+	}
+
+	return m, nil
+}
+
+func hasSignature(def api.FunctionDefinition, params, results []api.ValueType) bool {
+	return string(def.ParamTypes()) == string(params) && string(def.ResultTypes()) == string(results)
+}
+
+// functions returns the template functions of the module.
+func (m *wasmModule) functions() template.FuncMap {
+	funcs := make(template.FuncMap, len(m.exports))
+	for _, export := range m.exports {
+		funcs[strings.TrimPrefix(export, wasmFunctionPrefix)] = func(args ...any) (any, error) {
+			return m.call(export, args)
+		}
+	}
+
+	return funcs
+}
+
+// call calls an exported function in a new instance of the module.
+func (m *wasmModule) call(export string, args []any) (any, error) {
+	in, err := json.Marshal(args)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot marshal arguments")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), m.timeout)
+	defer cancel()
+
+	// Each call runs in a new anonymous instance, so calls don't share state
+	// and can run concurrently.
+	mod, err := m.runtime.InstantiateModule(ctx, m.module, wazero.NewModuleConfig().WithName("").WithStartFunctions("_initialize"))
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot instantiate module")
+	}
+	defer mod.Close(ctx) //nolint:errcheck // the instance isn't used anymore
+
+	res, err := mod.ExportedFunction("alloc").Call(ctx, uint64(len(in)))
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot allocate memory for arguments")
+	}
+	ptr := api.DecodeU32(res[0])
+	if !mod.Memory().Write(ptr, in) {
+		return nil, errors.New("cannot write arguments: out of memory range")
+	}
+
+	res, err = mod.ExportedFunction(export).Call(ctx, uint64(ptr), uint64(len(in)))
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot call %s", export)
+	}
+	outPtr, outLen := uint32(res[0]>>32), uint32(res[0])
+	out, ok := mod.Memory().Read(outPtr, outLen)
+	if !ok {
+		return nil, errors.New("cannot read output: out of memory range")
+	}
+
+	var o struct {
+		Result any     `json:"result"`
+		Error  *string `json:"error"`
+	}
+	if err := json.Unmarshal(out, &o); err != nil {
+		return nil, errors.Wrap(err, "cannot unmarshal output")
+	}
+	if o.Error != nil {
+		return nil, errors.New(*o.Error)
+	}
+
+	return o.Result, nil
+}
@@ -0,0 +1,235 @@
+package render
+
+import (
+	"bytes"
+	"context"
+	"maps"
+	"slices"
+	"testing"
+	"testing/fstest"
+	"text/template"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+// wasmModuleBytes returns a WASM module with the template functions hello,
+// which returns "hello", fail, which returns an error, and loop, which never
+// returns.
+func wasmModuleBytes(imports bool) []byte {
+	uleb := func(v uint64) []byte {
+		var b []byte
+		for {
+			c := byte(v & 0x7f)
+			v >>= 7
+			if v != 0 {
+				c |= 0x80
+			}
+			b = append(b, c)
+			if v == 0 {
+				return b
+			}
+		}
+	}
+	sleb := func(v int64) []byte {
+		var b []byte
+		for {
+			c := byte(v & 0x7f)
+			v >>= 7
+			if (v == 0 && c&0x40 == 0) || (v == -1 && c&0x40 != 0) {
+				return append(b, c)
+			}
+			b = append(b, c|0x80)
+		}
+	}
+	vec := func(items ...[]byte) []byte {
+		b := uleb(uint64(len(items)))
+		for _, i := range items {
+			b = append(b, i...)
+		}
+		return b
+	}
+	name := func(s string) []byte { return append(uleb(uint64(len(s))), s...) }
+	section := func(id byte, payload []byte) []byte {
+		return append(append([]byte{id}, uleb(uint64(len(payload)))...), payload...)
+	}
+	body := func(instrs ...byte) []byte {
+		b := append([]byte{0x00}, instrs...)
+		return append(uleb(uint64(len(b))), b...)
+	}
+	data := func(offset int64, s string) []byte {
+		b := append([]byte{0x00, 0x41}, sleb(offset)...)
+		b = append(b, 0x0b)
+		return append(b, name(s)...)
+	}
+	packed := func(ptr, length int) []byte {
+		return append([]byte{0x42}, sleb(int64(ptr)<<32|int64(length))...)
+	}
+
+	const (
+		hello = `{"result":"hello"}`
+		fail  = `{"error":"boom"}`
+	)
+
+	m := []byte{0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00}
+	m = append(m, section(1, vec(
+		[]byte{0x60, 0x01, 0x7f, 0x01, 0x7f},       // (i32) -> i32
+		[]byte{0x60, 0x02, 0x7f, 0x7f, 0x01, 0x7e}, // (i32, i32) -> i64
+		[]byte{0x60, 0x00, 0x00},                   // () -> ()
+	))...)
+	if imports {
+		m = append(m, section(2, vec(append(append(name("env"), name("now")...), 0x00, 0x02)))...)
+	}
+	m = append(m, section(3, vec([]byte{0x00}, []byte{0x01}, []byte{0x01}, []byte{0x01}))...)
+	m = append(m, section(5, vec([]byte{0x00, 0x01}))...)
+	idx := func(i byte) byte {
+		if imports {
+			return i + 1
+		}
+		return i
+	}
+	m = append(m, section(7, vec(
+		append(name("memory"), 0x02, 0x00),
+		append(name("alloc"), 0x00, idx(0)),
+		append(name("tmpl_hello"), 0x00, idx(1)),
+		append(name("tmpl_fail"), 0x00, idx(2)),
+		append(name("tmpl_loop"), 0x00, idx(3)),
+	))...)
+	m = append(m, section(10, vec(
+		body(0x41, 0x80, 0x08, 0x0b), // i32.const 1024
+		body(append(packed(0, len(hello)), 0x0b)...),
+		body(append(packed(64, len(fail)), 0x0b)...),
+		body(0x03, 0x40, 0x0c, 0x00, 0x0b, 0x00, 0x0b), // loop br 0 end unreachable
+	))...)
+	m = append(m, section(11, vec(data(0, hello), data(64, fail)))...)
+
+	return m
+}
+
+func Test_loadWASMModules(t *testing.T) {
+	type want struct {
+		out string
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		module []byte
+		tmpl   string
+		want   want
+	}{
+		"Result": {
+			reason: "Should return the result of the function",
+			module: wasmModuleBytes(false),
+			tmpl:   `{{ hello "a" 1 }}`,
+			want:   want{out: "hello"},
+		},
+		"Error": {
+			reason: "Should return the error of the function",
+			module: wasmModuleBytes(false),
+			tmpl:   `{{ fail }}`,
+			want:   want{err: cmpopts.AnyError},
+		},
+		"Timeout": {
+			reason: "Should stop functions that exceed the timeout",
+			module: wasmModuleBytes(false),
+			tmpl:   `{{ loop }}`,
+			want:   want{err: cmpopts.AnyError},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			fsys := fstest.MapFS{"ext/example.wasm": {Data: tc.module}, "ext/README.md": {Data: []byte("not a module")}}
+			modules, err := loadWASMModules(context.Background(), fsys, "ext", WASMConfig{Timeout: 100 * time.Millisecond})
+			if err != nil {
+				t.Fatalf("loadWASMModules(...): %v", err)
+			}
+			if diff := cmp.Diff([]string{"example"}, slices.Collect(maps.Keys(modules))); diff != "" {
+				t.Fatalf("loadWASMModules(...): -want modules, +got modules:\n%s", diff)
+			}
+
+			tpl := template.Must(template.New("").Funcs(modules["example"]).Parse(tc.tmpl))
+			buf := &bytes.Buffer{}
+			err = tpl.Execute(buf, nil)
+			if diff := cmp.Diff(tc.want.out, buf.String()); diff != "" {
+				t.Errorf("%s\nExecute(...): -want, +got:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("%s\nExecute(...): -want err, +got err:\n%s", tc.reason, diff)
+			}
+		})
+	}
+
+	t.Run("Imports", func(t *testing.T) {
+		fsys := fstest.MapFS{"ext/example.wasm": {Data: wasmModuleBytes(true)}}
+		if _, err := loadWASMModules(context.Background(), fsys, "ext", WASMConfig{}); err == nil {
+			t.Errorf("loadWASMModules(...): want error for module that imports host functions")
+		}
+	})
+}
+
+func TestLoadWASMFunctions(t *testing.T) {
+	RegisterFunctions("example", template.FuncMap{"costCenter": func() string { return "cc-42" }})
+	t.Cleanup(func() {
+		extensionsMu.Lock()
+		defer extensionsMu.Unlock()
+		delete(extensions, "example")
+		delete(extensions, "other")
+	})
+
+	fsys := fstest.MapFS{"ext/example.wasm": {Data: wasmModuleBytes(false)}, "ext/other.wasm": {Data: wasmModuleBytes(false)}}
+	if _, err := LoadWASMFunctions(context.Background(), fsys, "ext", WASMConfig{}); err == nil {
+		t.Errorf("LoadWASMFunctions(...): want error for module that is already registered")
+	}
+	if diff := cmp.Diff([]string{"example"}, RegisteredModules()); diff != "" {
+		t.Errorf("RegisteredModules(): want no module registered on error, -want, +got:\n%s", diff)
+	}
+}
+
+func TestRegisterFunctions(t *testing.T) {
+	RegisterFunctions("test", template.FuncMap{"costCenter": func() string { return "cc-42" }})
+	t.Cleanup(func() {
+		extensionsMu.Lock()
+		defer extensionsMu.Unlock()
+		delete(extensions, "test")
+	})
+
+	tpl, err := GetNewTemplateWithFunctionMaps(nil).Parse(`{{ costCenter }}`)
+	if err != nil {
+		t.Fatalf("Parse(...): %v", err)
+	}
+	buf := &bytes.Buffer{}
+	if err := tpl.Execute(buf, nil); err != nil {
+		t.Fatalf("Execute(...): %v", err)
+	}
+	if diff := cmp.Diff("cc-42", buf.String()); diff != "" {
+		t.Errorf("Execute(...): -want, +got:\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"test"}, RegisteredModules()); diff != "" {
+		t.Errorf("RegisteredModules(): -want, +got:\n%s", diff)
+	}
+
+	// Extensions take precedence over built-in functions, and the supplied
+	// functions over extensions.
+	RegisterFunctions("precedence", template.FuncMap{
+		"include": func(...any) string { return "extension" },
+		"team":    func() string { return "extension" },
+	})
+	t.Cleanup(func() {
+		extensionsMu.Lock()
+		defer extensionsMu.Unlock()
+		delete(extensions, "precedence")
+	})
+	tpl, err = GetNewTemplateWithFunctionMaps(nil, template.FuncMap{"team": func() string { return "caller" }}).Parse(`{{ include "x" . }}:{{ team }}`)
+	if err != nil {
+		t.Fatalf("Parse(...): %v", err)
+	}
+	buf.Reset()
+	if err := tpl.Execute(buf, nil); err != nil {
+		t.Fatalf("Execute(...): %v", err)
+	}
+	if diff := cmp.Diff("extension:caller", buf.String()); diff != "" {
+		t.Errorf("Execute(...): -want, +got:\n%s", diff)
+	}
+}